@@ -9,6 +9,8 @@ import (
 type ApplicationPool struct {
 	Id                    string
 	Name                  string
+	State                 string
+	AutoStart             bool
 	StartMode             string
 	PipelineMode          string
 	ManagedRuntimeVersion string
@@ -17,22 +19,29 @@ type ApplicationPool struct {
 	CPU                   CPU
 	ProcessModel          ProcessModel
 	Recycling             Recycling
-	RapidFailProtection   Failure
-	//ProcessOrphaning      ProcessOrphaning    `json:"Failure"`
+	Failure               Failure
+	EnvironmentVariables  map[string]string
 }
 
 type ProcessModel struct {
 	IdentityType      string
 	Username          string
+	Password          *string
 	LoadUserProfile   bool
-	IdleTimeout       int
+	IdleTimeout       TimeSpan
 	IdleTimeoutAction string
 	MaxProcesses      int
 	PingingEnabled    bool
-	PingInterval      int
-	PingResponseTime  int
-	StartupTimeLimit  int
-	ShutdownTimeLimit int
+	PingInterval      TimeSpan
+	PingResponseTime  TimeSpan
+	StartupTimeLimit  TimeSpan
+	ShutdownTimeLimit TimeSpan
+	// LogIdleTimeoutEvent maps logEventOnProcessModel, whose only flag is IdleTimeout
+	LogIdleTimeoutEvent           bool
+	LogonType                     string
+	ManualGroupMembership         bool
+	SetProfileEnvironment         bool
+	RequestQueueDelegatorIdentity string
 }
 
 type applicationPoolResponse struct {
@@ -44,11 +53,21 @@ type applicationPoolResponse struct {
 	ManagedRuntimeVersion string           `json:"ManagedRuntimeVersion"`
 	Enable32BitWin64      bool             `json:"Enable32BitAppOnWin64"`
 	QueueLength           int64            `json:"QueueLength"`
-	CPU                   CPU              `json:"Cpu"`
+	CPU                   JsonCPU          `json:"Cpu"`
 	ProcessModel          JsonProcessModel `json:"ProcessModel"`
-	Recycling             Recycling        `json:"Recycling"`
-	RapidFailProtection   Failure          `json:"Failure"`
-	//ProcessOrphaning      ProcessOrphaning    `json:"Failure"`
+	Recycling             JsonRecycling    `json:"Recycling"`
+	Failure               JsonFailure      `json:"Failure"`
+
+	CPUNumaNodeAssignment   NumaNodeAssignment   `json:"CpuNumaNodeAssignment"`
+	CPUNumaNodeAffinityMode NumaNodeAffinityMode `json:"CpuNumaNodeAffinityMode"`
+
+	ProcessModelSetProfileEnvironment         bool   `json:"ProcessModelSetProfileEnvironment"`
+	ProcessModelRequestQueueDelegatorIdentity string `json:"ProcessModelRequestQueueDelegatorIdentity"`
+
+	RecyclingPeriodicRestartTime     TimeSpan `json:"RecyclingPeriodicRestartTime"`
+	RecyclingPeriodicRestartSchedule []string `json:"RecyclingPeriodicRestartSchedule"`
+
+	EnvironmentVariables map[string]string `json:"EnvironmentVariables"`
 }
 
 type AppPoolState string
@@ -56,70 +75,140 @@ type StartMode string
 type PipelineMode string
 
 type CPU struct {
-	Limit int64 `json:"Limit"`
-	//LimitInterval            int64  `json:"limit_interval"`
-	Action                   string `json:"Action"`
-	ProcessorAffinityEnabled bool   `json:"SmpAffinitized"`
-	ProcessorAffinityMask32  int64  `json:"SmpProcessorAffinityMask"`
-	ProcessorAffinityMask64  int64  `json:"SmpProcessorAffinityMask2"`
+	Limit                    int
+	LimitInterval            TimeSpan
+	Action                   string
+	ProcessorAffinityEnabled bool
+	ProcessorAffinityMask32  int64
+	ProcessorAffinityMask64  int64
+	NumaNodeAssignment       string
+	NumaNodeAffinityMode     string
 }
 
+type JsonCPU struct {
+	Limit                    int64           `json:"Limit"`
+	LimitInterval            TimeSpan        `json:"ResetInterval"`
+	Action                   ProcessorAction `json:"Action"`
+	ProcessorAffinityEnabled bool            `json:"SmpAffinitized"`
+	ProcessorAffinityMask32  int64           `json:"SmpProcessorAffinityMask"`
+	ProcessorAffinityMask64  int64           `json:"SmpProcessorAffinityMask2"`
+}
+
+type ProcessorAction string
+type NumaNodeAssignment string
+type NumaNodeAffinityMode string
+
 type JsonProcessModel struct {
-	IdentityType      IdentityType      `json:"IdentityType"`
-	Username          string            `json:"UserName"`
-	LoadUserProfile   bool              `json:"LoadUserProfile"`
-	IdleTimeout       DurationMinutes   `json:"IdleTimeout"`
-	IdleTimeoutAction IdleTimeoutAction `json:"IdleTimeoutAction"`
-	MaxProcesses      int64             `json:"MaxProcesses"`
-	PingingEnabled    bool              `json:"PingingEnabled"`
-	PingInterval      DurationSeconds   `json:"PingInterval"`
-	PingResponseTime  DurationSeconds   `json:"PingResponseTime"`
-	StartupTimeLimit  DurationSeconds   `json:"StartupTimeLimit"`
-	ShutdownTimeLimit DurationSeconds   `json:"ShutdownTimeLimit"`
+	IdentityType           IdentityType      `json:"IdentityType"`
+	Username               string            `json:"UserName"`
+	LoadUserProfile        bool              `json:"LoadUserProfile"`
+	IdleTimeout            TimeSpan          `json:"IdleTimeout"`
+	IdleTimeoutAction      IdleTimeoutAction `json:"IdleTimeoutAction"`
+	MaxProcesses           int64             `json:"MaxProcesses"`
+	PingingEnabled         bool              `json:"PingingEnabled"`
+	PingInterval           TimeSpan          `json:"PingInterval"`
+	PingResponseTime       TimeSpan          `json:"PingResponseTime"`
+	StartupTimeLimit       TimeSpan          `json:"StartupTimeLimit"`
+	ShutdownTimeLimit      TimeSpan          `json:"ShutdownTimeLimit"`
+	LogEventOnProcessModel int64             `json:"LogEventOnProcessModel"`
+	LogonType              LogonType         `json:"LogonType"`
+	ManualGroupMembership  bool              `json:"ManualGroupMembership"`
 }
 
 type IdentityType string
 type IdleTimeoutAction string
+type LogonType string
 
 type Failure struct {
-	OrphanWorkerProcessEnabled    bool   `json:"OrphanWorkerProcess"`
-	OrphanActionExe               string `json:"OrphanActionExe"`
-	OrphanActionParams            string `json:"OrphanActionParams"`
-	RapidFailProtectionEnabled    bool   `json:"RapidFailProtection"`
-	LoadBalancerCapabilities      int    `json:"LoadBalancerCapabilities"`
-	RapidFailProtectionMaxCrashes int64  `json:"RapidFailProtectionMaxCrashes"`
-	AutoShutdownExe               string `json:"AutoShutdownExe"`
-	AutoShutdownParams            string `json:"AutoShutdownParams"`
-	//Interval                 int64  `json:"interval"`
+	RapidFailProtectionEnabled    bool
+	RapidFailProtectionInterval   TimeSpan
+	RapidFailProtectionMaxCrashes int
+	AutoShutdownExe               string
+	AutoShutdownParams            string
+	LoadBalancerCapabilities      string
+	OrphanWorkerProcessEnabled    bool
+	OrphanActionExe               string
+	OrphanActionParams            string
 }
 
+type JsonFailure struct {
+	OrphanWorkerProcessEnabled    bool                     `json:"OrphanWorkerProcess"`
+	OrphanActionExe               string                   `json:"OrphanActionExe"`
+	OrphanActionParams            string                   `json:"OrphanActionParams"`
+	RapidFailProtectionEnabled    bool                     `json:"RapidFailProtection"`
+	RapidFailProtectionInterval   TimeSpan                 `json:"RapidFailProtectionInterval"`
+	LoadBalancerCapabilities      LoadBalancerCapabilities `json:"LoadBalancerCapabilities"`
+	RapidFailProtectionMaxCrashes int64                    `json:"RapidFailProtectionMaxCrashes"`
+	AutoShutdownExe               string                   `json:"AutoShutdownExe"`
+	AutoShutdownParams            string                   `json:"AutoShutdownParams"`
+}
+
+type LoadBalancerCapabilities string
+
 type Recycling struct {
-	DisableOverlappedRecycle     bool            `json:"DisallowOverlappingRotation"`
-	DisableRecycleOnConfigChange bool            `json:"DisallowRotationOnConfigChange"`
-	PeriodicRestart              PeriodicRestart `json:"PeriodicRestart"`
+	DisableOverlappedRecycle     bool
+	DisableRecycleOnConfigChange bool
+	LogEvents                    LogEvents
+	PeriodicRestart              PeriodicRestart
 }
 
 type LogEvents struct {
-	Time           bool `json:"time"`
-	Requests       bool `json:"requests"`
-	Schedule       bool `json:"schedule"`
-	Memory         bool `json:"memory"`
-	IsapiUnhealthy bool `json:"isapi_unhealthy"`
-	OnDemand       bool `json:"on_demand"`
-	ConfigChange   bool `json:"config_change"`
-	PrivateMemory  bool `json:"private_memory"`
+	Time           bool
+	Requests       bool
+	Schedule       bool
+	Memory         bool
+	IsapiUnhealthy bool
+	OnDemand       bool
+	ConfigChange   bool
+	PrivateMemory  bool
 }
 
 type PeriodicRestart struct {
-	//TimeInterval  int64         `json:"time_interval"`
+	TimeInterval  TimeSpan
+	Schedule      []string
+	PrivateMemory int
+	RequestLimit  int
+	VirtualMemory int
+}
+
+type JsonRecycling struct {
+	DisableOverlappedRecycle     bool                `json:"DisallowOverlappingRotation"`
+	DisableRecycleOnConfigChange bool                `json:"DisallowRotationOnConfigChange"`
+	LogEventOnRecycle            int64               `json:"LogEventOnRecycle"`
+	PeriodicRestart              JsonPeriodicRestart `json:"PeriodicRestart"`
+}
+
+type JsonPeriodicRestart struct {
 	PrivateMemory int64 `json:"PrivateMemory"`
 	RequestLimit  int64 `json:"Requests"`
 	VirtualMemory int64 `json:"Memory"`
 }
 
+// Flags of the recycling.logEventOnRecycle attribute
+const (
+	logEventTime           = 1
+	logEventRequests       = 2
+	logEventSchedule       = 4
+	logEventMemory         = 8
+	logEventIsapiUnhealthy = 16
+	logEventOnDemand       = 32
+	logEventConfigChange   = 64
+	logEventPrivateMemory  = 128
+)
+
+// appPoolProperties projects the application pools of the pipeline into the shape of applicationPoolResponse
+const appPoolProperties = `Select-Object *,
+		@{Name='CpuNumaNodeAssignment'; Expression={ $_.Cpu.GetAttributeValue('numaNodeAssignment') }},
+		@{Name='CpuNumaNodeAffinityMode'; Expression={ $_.Cpu.GetAttributeValue('numaNodeAffinityMode') }},
+		@{Name='ProcessModelSetProfileEnvironment'; Expression={ try { $_.ProcessModel.GetAttributeValue('setProfileEnvironment') } catch { $false } }},
+		@{Name='ProcessModelRequestQueueDelegatorIdentity'; Expression={ try { $_.ProcessModel.GetAttributeValue('requestQueueDelegatorIdentity') } catch { '' } }},
+		@{Name='RecyclingPeriodicRestartTime'; Expression={ $_.Recycling.PeriodicRestart.Time }},
+		@{Name='RecyclingPeriodicRestartSchedule'; Expression={ ,@($_.Recycling.PeriodicRestart.Schedule | ForEach-Object { $_.Time.ToString('hh\:mm') }) }},
+		@{Name='EnvironmentVariables'; Expression={ $variables = @{}; try { $_.GetCollection('environmentVariables') | ForEach-Object { $variables[$_['name']] = $_['value'] } } catch {}; $variables }}`
+
 func (client Client) GetAppPool(name string) (*ApplicationPool, error) {
 	var response applicationPoolResponse
-	command := fmt.Sprintf("Get-IISAppPool -Name '%s' -WarningAction Stop | ConvertTo-Json -Compress", name)
+	command := fmt.Sprintf(`Get-IISAppPool -Name '%s' -WarningAction Stop | %s | ConvertTo-Json -Compress`, name, appPoolProperties)
 	bytes, err := client.Execute(command)
 	if err != nil {
 		return nil, err
@@ -134,6 +223,27 @@ func (client Client) GetAppPool(name string) (*ApplicationPool, error) {
 	return appPool, nil
 }
 
+func (client Client) ListAppPools() ([]ApplicationPool, error) {
+	var responses []applicationPoolResponse
+	// Each pool is converted on its own so that the nesting depth matches GetAppPool
+	command := fmt.Sprintf(`'[' + ((Get-IISAppPool | %s | ForEach-Object { $_ | ConvertTo-Json -Compress }) -join ',') + ']'`, appPoolProperties)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &responses); err != nil {
+		return nil, err
+	}
+
+	appPools := []ApplicationPool{}
+	for _, response := range responses {
+		appPools = append(appPools, *mapToApplicationPool(&response))
+	}
+
+	return appPools, nil
+}
+
 func (client Client) DeleteAppPool(name string) error {
 	command := fmt.Sprintf("Remove-WebAppPool -Name %q", name)
 	_, err := client.Execute(command)
@@ -143,6 +253,66 @@ func (client Client) DeleteAppPool(name string) error {
 	return nil
 }
 
+// AppPoolUsage is a web site or a web application running in an application pool
+type AppPoolUsage struct {
+	Site string
+	// Application is empty for the root application of the site
+	Application string
+}
+
+func (usage AppPoolUsage) String() string {
+	if usage.Application == "" {
+		return fmt.Sprintf("web site '%s'", usage.Site)
+	}
+
+	return fmt.Sprintf("web application '%s/%s'", usage.Site, usage.Application)
+}
+
+// GetAppPoolUsages lists the web sites and web applications which still run in the application pool
+func (client Client) GetAppPoolUsages(name string) ([]AppPoolUsage, error) {
+	webSites, err := client.ListWebSites()
+	if err != nil {
+		return nil, err
+	}
+
+	webApplications, err := client.ListWebApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := []AppPoolUsage{}
+	for _, webSite := range webSites {
+		if strings.EqualFold(webSite.ApplicationPoolName, name) {
+			usages = append(usages, AppPoolUsage{Site: webSite.Name})
+		}
+	}
+
+	for _, webApplication := range webApplications {
+		if strings.EqualFold(webApplication.ApplicationPoolName, name) {
+			usages = append(usages, AppPoolUsage{Site: webApplication.Site, Application: webApplication.Name})
+		}
+	}
+
+	return usages, nil
+}
+
+// ReassignAppPoolUsages moves the web sites and web applications to another application pool
+func (client Client) ReassignAppPoolUsages(usages []AppPoolUsage, appPoolName string) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module WebAdministration;`)
+	for _, usage := range usages {
+		path := fmt.Sprintf(`IIS:\Sites\%s`, usage.Site)
+		if usage.Application != "" {
+			path = fmt.Sprintf(`%s\%s`, path, strings.ReplaceAll(usage.Application, "/", `\`))
+		}
+
+		sb.WriteString(fmt.Sprintf(`Set-ItemProperty -Path %s applicationPool %s;`, toPowerShellString(path), toPowerShellString(appPoolName)))
+	}
+
+	_, err := client.Execute(sb.String())
+	return err
+}
+
 func (client Client) CreateAppPool(appPool ApplicationPool) (*ApplicationPool, error) {
 	_, err := client.Execute(fmt.Sprintf(`New-WebAppPool -Name %q;`, appPool.Name))
 	if err == nil {
@@ -156,6 +326,45 @@ func (client Client) CreateAppPool(appPool ApplicationPool) (*ApplicationPool, e
 	return client.GetAppPool(appPool.Name)
 }
 
+func (client Client) GetAppPoolState(name string) (string, error) {
+	bytes, err := client.Execute(fmt.Sprintf("(Get-WebAppPoolState -Name %q).Value", name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(*bytes)), nil
+}
+
+func (client Client) StartAppPool(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Start-WebAppPool -Name %q", name))
+	return err
+}
+
+func (client Client) StopAppPool(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Stop-WebAppPool -Name %q", name))
+	return err
+}
+
+func (client Client) RecycleAppPool(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Restart-WebAppPool -Name %q", name))
+	return err
+}
+
+func (client Client) GetAppPoolWorkerProcessIds(name string) ([]int, error) {
+	command := fmt.Sprintf(`ConvertTo-Json -Compress -InputObject @((Get-IISAppPool -Name '%s').WorkerProcesses | ForEach-Object { $_.ProcessId })`, name)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var processIds []int
+	if err = json.Unmarshal(*bytes, &processIds); err != nil {
+		return nil, err
+	}
+
+	return processIds, nil
+}
+
 func (client Client) UpdateAppPool(appPool ApplicationPool) error {
 	existingAppPool, err := client.GetAppPool(appPool.Name)
 	if err != nil {
@@ -175,22 +384,120 @@ func (client Client) updateAppPool(appPool ApplicationPool) error {
 	var sb strings.Builder
 	sb.WriteString(`Import-Module WebAdministration;`)
 	setProp := fmt.Sprintf(`Set-ItemProperty -Path 'IIS:\AppPools\%s'`, appPool.Name)
+	writeAppPoolProperties(&sb, setProp, appPool)
+	sb.WriteString(fmt.Sprintf(`Clear-ItemProperty -Path 'IIS:\AppPools\%s' -Name recycling.periodicRestart.schedule;`, appPool.Name))
+	for _, schedule := range appPool.Recycling.PeriodicRestart.Schedule {
+		sb.WriteString(fmt.Sprintf(`New-ItemProperty -Path 'IIS:\AppPools\%s' -Name recycling.periodicRestart.schedule -Value @{value=%q};`, appPool.Name, schedule))
+	}
+	_, err := client.Execute(sb.String())
+	if err != nil {
+		return err
+	}
+
+	return client.updateAppPoolEnvironmentVariables(appPool)
+}
+
+// writeAppPoolProperties writes the commands setting every attribute of the pool, each one prefixed by setProp
+// so that they apply to a single application pool as well as to the server defaults
+func writeAppPoolProperties(sb *strings.Builder, setProp string, appPool ApplicationPool) {
+	sb.WriteString(fmt.Sprintf(`%s autoStart %q;`, setProp, toPascalCase(appPool.AutoStart)))
 	sb.WriteString(fmt.Sprintf(`%s startMode %q;`, setProp, appPool.StartMode))
 	sb.WriteString(fmt.Sprintf(`%s managedPipelineMode %q;`, setProp, appPool.PipelineMode))
 	sb.WriteString(fmt.Sprintf(`%s managedRuntimeVersion %q;`, setProp, appPool.ManagedRuntimeVersion))
 	sb.WriteString(fmt.Sprintf(`%s enable32BitAppOnWin64 %q;`, setProp, toPascalCase(appPool.Enable32BitWin64)))
 	sb.WriteString(fmt.Sprintf(`%s queueLength %d;`, setProp, appPool.QueueLength))
 	sb.WriteString(fmt.Sprintf(`%s processModel.identityType %q;`, setProp, appPool.ProcessModel.IdentityType))
-	sb.WriteString(fmt.Sprintf(`%s processModel.username %q;`, setProp, appPool.ProcessModel.Username))
+	// The password is never read back, so the credentials are left alone by the rollbacks, which have none
+	if appPool.ProcessModel.Password != nil {
+		sb.WriteString(fmt.Sprintf(`%s processModel.username %s;`, setProp, toPowerShellString(appPool.ProcessModel.Username)))
+		sb.WriteString(fmt.Sprintf(`%s processModel.password %s;`, setProp, toPowerShellString(*appPool.ProcessModel.Password)))
+	}
+
 	sb.WriteString(fmt.Sprintf(`%s processModel.loadUserProfile %q;`, setProp, toPascalCase(appPool.ProcessModel.LoadUserProfile)))
-	sb.WriteString(fmt.Sprintf(`%s processModel.idleTimeout %q;`, setProp, DurationMinutes(appPool.ProcessModel.IdleTimeout).toTimeString()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.idleTimeout %q;`, setProp, appPool.ProcessModel.IdleTimeout.String()))
 	sb.WriteString(fmt.Sprintf(`%s processModel.idleTimeoutAction %q;`, setProp, appPool.ProcessModel.IdleTimeoutAction))
 	sb.WriteString(fmt.Sprintf(`%s processModel.maxProcesses %d;`, setProp, appPool.ProcessModel.MaxProcesses))
 	sb.WriteString(fmt.Sprintf(`%s processModel.pingingEnabled %q;`, setProp, toPascalCase(appPool.ProcessModel.PingingEnabled)))
-	sb.WriteString(fmt.Sprintf(`%s processModel.pingInterval %q;`, setProp, DurationSeconds(appPool.ProcessModel.PingInterval).toTimeString()))
-	sb.WriteString(fmt.Sprintf(`%s processModel.pingResponseTime %q;`, setProp, DurationSeconds(appPool.ProcessModel.PingResponseTime).toTimeString()))
-	sb.WriteString(fmt.Sprintf(`%s processModel.startupTimeLimit %q;`, setProp, DurationSeconds(appPool.ProcessModel.StartupTimeLimit).toTimeString()))
-	sb.WriteString(fmt.Sprintf(`%s processModel.shutdownTimeLimit %q;`, setProp, DurationSeconds(appPool.ProcessModel.ShutdownTimeLimit).toTimeString()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.pingInterval %q;`, setProp, appPool.ProcessModel.PingInterval.String()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.pingResponseTime %q;`, setProp, appPool.ProcessModel.PingResponseTime.String()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.startupTimeLimit %q;`, setProp, appPool.ProcessModel.StartupTimeLimit.String()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.shutdownTimeLimit %q;`, setProp, appPool.ProcessModel.ShutdownTimeLimit.String()))
+	sb.WriteString(fmt.Sprintf(`%s processModel.logEventOnProcessModel %s;`, setProp, logIdleTimeoutEventValue(appPool.ProcessModel.LogIdleTimeoutEvent)))
+	sb.WriteString(fmt.Sprintf(`%s processModel.logonType %q;`, setProp, appPool.ProcessModel.LogonType))
+	sb.WriteString(fmt.Sprintf(`%s processModel.manualGroupMembership %q;`, setProp, toPascalCase(appPool.ProcessModel.ManualGroupMembership)))
+	writeOptionalProperty(sb, setProp, "processModel.setProfileEnvironment", fmt.Sprintf("%q", toPascalCase(appPool.ProcessModel.SetProfileEnvironment)), !appPool.ProcessModel.SetProfileEnvironment)
+	writeOptionalProperty(sb, setProp, "processModel.requestQueueDelegatorIdentity", toPowerShellString(appPool.ProcessModel.RequestQueueDelegatorIdentity), appPool.ProcessModel.RequestQueueDelegatorIdentity == "")
+	sb.WriteString(fmt.Sprintf(`%s cpu.limit %d;`, setProp, appPool.CPU.Limit))
+	sb.WriteString(fmt.Sprintf(`%s cpu.resetInterval %q;`, setProp, appPool.CPU.LimitInterval.String()))
+	sb.WriteString(fmt.Sprintf(`%s cpu.action %q;`, setProp, appPool.CPU.Action))
+	sb.WriteString(fmt.Sprintf(`%s cpu.smpAffinitized %q;`, setProp, toPascalCase(appPool.CPU.ProcessorAffinityEnabled)))
+	sb.WriteString(fmt.Sprintf(`%s cpu.smpProcessorAffinityMask %d;`, setProp, appPool.CPU.ProcessorAffinityMask32))
+	sb.WriteString(fmt.Sprintf(`%s cpu.smpProcessorAffinityMask2 %d;`, setProp, appPool.CPU.ProcessorAffinityMask64))
+	sb.WriteString(fmt.Sprintf(`%s cpu.numaNodeAssignment %q;`, setProp, appPool.CPU.NumaNodeAssignment))
+	sb.WriteString(fmt.Sprintf(`%s cpu.numaNodeAffinityMode %q;`, setProp, appPool.CPU.NumaNodeAffinityMode))
+	sb.WriteString(fmt.Sprintf(`%s recycling.disallowOverlappingRotation %q;`, setProp, toPascalCase(appPool.Recycling.DisableOverlappedRecycle)))
+	sb.WriteString(fmt.Sprintf(`%s recycling.disallowRotationOnConfigChange %q;`, setProp, toPascalCase(appPool.Recycling.DisableRecycleOnConfigChange)))
+	sb.WriteString(fmt.Sprintf(`%s recycling.logEventOnRecycle %v;`, setProp, appPool.Recycling.LogEvents.toAttributeValue()))
+	sb.WriteString(fmt.Sprintf(`%s recycling.periodicRestart.time %q;`, setProp, appPool.Recycling.PeriodicRestart.TimeInterval.String()))
+	sb.WriteString(fmt.Sprintf(`%s recycling.periodicRestart.requests %d;`, setProp, appPool.Recycling.PeriodicRestart.RequestLimit))
+	sb.WriteString(fmt.Sprintf(`%s recycling.periodicRestart.memory %d;`, setProp, appPool.Recycling.PeriodicRestart.VirtualMemory))
+	sb.WriteString(fmt.Sprintf(`%s recycling.periodicRestart.privateMemory %d;`, setProp, appPool.Recycling.PeriodicRestart.PrivateMemory))
+	sb.WriteString(fmt.Sprintf(`%s failure.rapidFailProtection %q;`, setProp, toPascalCase(appPool.Failure.RapidFailProtectionEnabled)))
+	sb.WriteString(fmt.Sprintf(`%s failure.rapidFailProtectionInterval %q;`, setProp, appPool.Failure.RapidFailProtectionInterval.String()))
+	sb.WriteString(fmt.Sprintf(`%s failure.rapidFailProtectionMaxCrashes %d;`, setProp, appPool.Failure.RapidFailProtectionMaxCrashes))
+	sb.WriteString(fmt.Sprintf(`%s failure.autoShutdownExe %s;`, setProp, toPowerShellString(appPool.Failure.AutoShutdownExe)))
+	sb.WriteString(fmt.Sprintf(`%s failure.autoShutdownParams %s;`, setProp, toPowerShellString(appPool.Failure.AutoShutdownParams)))
+	sb.WriteString(fmt.Sprintf(`%s failure.loadBalancerCapabilities %q;`, setProp, appPool.Failure.LoadBalancerCapabilities))
+	sb.WriteString(fmt.Sprintf(`%s failure.orphanWorkerProcess %q;`, setProp, toPascalCase(appPool.Failure.OrphanWorkerProcessEnabled)))
+	sb.WriteString(fmt.Sprintf(`%s failure.orphanActionExe %s;`, setProp, toPowerShellString(appPool.Failure.OrphanActionExe)))
+	sb.WriteString(fmt.Sprintf(`%s failure.orphanActionParams %s;`, setProp, toPowerShellString(appPool.Failure.OrphanActionParams)))
+}
+
+// unrecognizedAttributeErrors match the errors of the hosts whose configuration schema lacks an attribute
+const unrecognizedAttributeErrors = `is not found on|Unrecognized attribute`
+
+// writeOptionalProperty sets an attribute which older hosts may not know, such as logFile.logTargetW3C before IIS 8.5
+// or processModel.setProfileEnvironment before IIS 10. The hosts not recognizing it are left alone as long as the
+// requested value is the default one, any other error failing the command
+func writeOptionalProperty(sb *strings.Builder, setProp string, name string, value string, isDefault bool) {
+	sb.WriteString(fmt.Sprintf(`try { %s %s %s -ErrorAction Stop } catch { if ($%t -or $_.Exception.Message -notmatch '%s') { throw } };`, setProp, name, value, !isDefault, unrecognizedAttributeErrors))
+}
+
+func logIdleTimeoutEventValue(enabled bool) string {
+	if enabled {
+		return `"IdleTimeout"`
+	}
+
+	return "0"
+}
+
+// updateAppPoolEnvironmentVariables adds, changes and removes the variables one by one so that the unchanged ones are left untouched
+func (client Client) updateAppPoolEnvironmentVariables(appPool ApplicationPool) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module IISAdministration; $desired = @{};`)
+	for name, value := range appPool.EnvironmentVariables {
+		sb.WriteString(fmt.Sprintf(`$desired[%s] = %s;`, toPowerShellString(name), toPowerShellString(value)))
+	}
+
+	// environmentVariables only exists since IIS 10, so older hosts are left alone unless variables are configured
+	sb.WriteString(fmt.Sprintf(`
+		$pool = Get-IISAppPool -Name '%s';
+		try { $variables = $pool.GetCollection('environmentVariables') } catch { if ($desired.Count -gt 0) { throw } else { return } }
+		Start-IISCommitDelay;
+		@($variables | Where-Object { -not $desired.ContainsKey($_['name']) }) | ForEach-Object { $variables.Remove($_) };
+		foreach ($name in $desired.Keys) {
+			$variable = $variables | Where-Object { $_['name'] -eq $name };
+			if ($variable -eq $null) {
+				$variable = $variables.CreateElement('add');
+				$variable['name'] = $name;
+				$variable['value'] = $desired[$name];
+				[void]$variables.Add($variable);
+			} elseif ($variable['value'] -cne $desired[$name]) {
+				$variable['value'] = $desired[$name];
+			}
+		}
+		Stop-IISCommitDelay;`, appPool.Name))
+
 	_, err := client.Execute(sb.String())
 	return err
 }
@@ -199,28 +506,110 @@ func mapToApplicationPool(response *applicationPoolResponse) *ApplicationPool {
 	return &ApplicationPool{
 		Id:                    response.Name,
 		Name:                  response.Name,
+		State:                 string(response.State),
+		AutoStart:             response.AutoStart,
 		StartMode:             string(response.StartMode),
 		PipelineMode:          string(response.PipelineMode),
 		ManagedRuntimeVersion: response.ManagedRuntimeVersion,
 		Enable32BitWin64:      response.Enable32BitWin64,
 		QueueLength:           int(response.QueueLength),
+		EnvironmentVariables:  response.EnvironmentVariables,
 		ProcessModel: ProcessModel{
-			IdentityType:      string(response.ProcessModel.IdentityType),
-			Username:          response.ProcessModel.Username,
-			LoadUserProfile:   response.ProcessModel.LoadUserProfile,
-			IdleTimeout:       int(response.ProcessModel.IdleTimeout),
-			IdleTimeoutAction: string(response.ProcessModel.IdleTimeoutAction),
-			MaxProcesses:      int(response.ProcessModel.MaxProcesses),
-			PingingEnabled:    response.ProcessModel.PingingEnabled,
-			PingInterval:      int(response.ProcessModel.PingInterval),
-			PingResponseTime:  int(response.ProcessModel.PingResponseTime),
-			StartupTimeLimit:  int(response.ProcessModel.StartupTimeLimit),
-			ShutdownTimeLimit: int(response.ProcessModel.ShutdownTimeLimit),
+			IdentityType:                  string(response.ProcessModel.IdentityType),
+			Username:                      response.ProcessModel.Username,
+			LoadUserProfile:               response.ProcessModel.LoadUserProfile,
+			IdleTimeout:                   response.ProcessModel.IdleTimeout,
+			IdleTimeoutAction:             string(response.ProcessModel.IdleTimeoutAction),
+			MaxProcesses:                  int(response.ProcessModel.MaxProcesses),
+			PingingEnabled:                response.ProcessModel.PingingEnabled,
+			PingInterval:                  response.ProcessModel.PingInterval,
+			PingResponseTime:              response.ProcessModel.PingResponseTime,
+			StartupTimeLimit:              response.ProcessModel.StartupTimeLimit,
+			ShutdownTimeLimit:             response.ProcessModel.ShutdownTimeLimit,
+			LogIdleTimeoutEvent:           response.ProcessModel.LogEventOnProcessModel&1 != 0,
+			LogonType:                     string(response.ProcessModel.LogonType),
+			ManualGroupMembership:         response.ProcessModel.ManualGroupMembership,
+			SetProfileEnvironment:         response.ProcessModelSetProfileEnvironment,
+			RequestQueueDelegatorIdentity: response.ProcessModelRequestQueueDelegatorIdentity,
+		},
+		CPU: CPU{
+			Limit:                    int(response.CPU.Limit),
+			LimitInterval:            response.CPU.LimitInterval,
+			Action:                   string(response.CPU.Action),
+			ProcessorAffinityEnabled: response.CPU.ProcessorAffinityEnabled,
+			ProcessorAffinityMask32:  response.CPU.ProcessorAffinityMask32,
+			ProcessorAffinityMask64:  response.CPU.ProcessorAffinityMask64,
+			NumaNodeAssignment:       string(response.CPUNumaNodeAssignment),
+			NumaNodeAffinityMode:     string(response.CPUNumaNodeAffinityMode),
+		},
+		Recycling: Recycling{
+			DisableOverlappedRecycle:     response.Recycling.DisableOverlappedRecycle,
+			DisableRecycleOnConfigChange: response.Recycling.DisableRecycleOnConfigChange,
+			LogEvents:                    toLogEvents(response.Recycling.LogEventOnRecycle),
+			PeriodicRestart: PeriodicRestart{
+				TimeInterval:  response.RecyclingPeriodicRestartTime,
+				Schedule:      response.RecyclingPeriodicRestartSchedule,
+				PrivateMemory: int(response.Recycling.PeriodicRestart.PrivateMemory),
+				RequestLimit:  int(response.Recycling.PeriodicRestart.RequestLimit),
+				VirtualMemory: int(response.Recycling.PeriodicRestart.VirtualMemory),
+			},
 		},
-		CPU: response.CPU,
+		Failure: Failure{
+			RapidFailProtectionEnabled:    response.Failure.RapidFailProtectionEnabled,
+			RapidFailProtectionInterval:   response.Failure.RapidFailProtectionInterval,
+			RapidFailProtectionMaxCrashes: int(response.Failure.RapidFailProtectionMaxCrashes),
+			AutoShutdownExe:               response.Failure.AutoShutdownExe,
+			AutoShutdownParams:            response.Failure.AutoShutdownParams,
+			LoadBalancerCapabilities:      string(response.Failure.LoadBalancerCapabilities),
+			OrphanWorkerProcessEnabled:    response.Failure.OrphanWorkerProcessEnabled,
+			OrphanActionExe:               response.Failure.OrphanActionExe,
+			OrphanActionParams:            response.Failure.OrphanActionParams,
+		},
+	}
+}
+
+func toLogEvents(flags int64) LogEvents {
+	return LogEvents{
+		Time:           flags&logEventTime != 0,
+		Requests:       flags&logEventRequests != 0,
+		Schedule:       flags&logEventSchedule != 0,
+		Memory:         flags&logEventMemory != 0,
+		IsapiUnhealthy: flags&logEventIsapiUnhealthy != 0,
+		OnDemand:       flags&logEventOnDemand != 0,
+		ConfigChange:   flags&logEventConfigChange != 0,
+		PrivateMemory:  flags&logEventPrivateMemory != 0,
 	}
 }
 
+func (logEvents LogEvents) toAttributeValue() string {
+	flags := []struct {
+		enabled bool
+		name    string
+	}{
+		{logEvents.Time, "Time"},
+		{logEvents.Requests, "Requests"},
+		{logEvents.Schedule, "Schedule"},
+		{logEvents.Memory, "Memory"},
+		{logEvents.IsapiUnhealthy, "IsapiUnhealthy"},
+		{logEvents.OnDemand, "OnDemand"},
+		{logEvents.ConfigChange, "ConfigChange"},
+		{logEvents.PrivateMemory, "PrivateMemory"},
+	}
+
+	var names []string
+	for _, flag := range flags {
+		if flag.enabled {
+			names = append(names, flag.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "0"
+	}
+
+	return fmt.Sprintf("%q", strings.Join(names, ","))
+}
+
 func (state *AppPoolState) UnmarshalJSON(data []byte) error {
 	var number int
 	if err := json.Unmarshal(data, &number); err != nil {
@@ -315,3 +704,92 @@ func (state *IdleTimeoutAction) UnmarshalJSON(data []byte) error {
 	}
 	return nil
 }
+
+func (state *LogonType) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 0:
+		*state = "LogonBatch"
+	case 1:
+		*state = "LogonService"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
+
+func (state *ProcessorAction) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 0:
+		*state = "NoAction"
+	case 1:
+		*state = "KillW3wp"
+	case 2:
+		*state = "Throttle"
+	case 3:
+		*state = "ThrottleUnderLoad"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
+
+func (state *NumaNodeAssignment) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 0:
+		*state = "MostAvailableMemory"
+	case 1:
+		*state = "WindowsScheduling"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
+
+func (state *NumaNodeAffinityMode) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 0:
+		*state = "Soft"
+	case 1:
+		*state = "Hard"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
+
+func (state *LoadBalancerCapabilities) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 1:
+		*state = "TcpLevel"
+	case 2:
+		*state = "HttpLevel"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
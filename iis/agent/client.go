@@ -2,21 +2,102 @@ package agent
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/rickedb/terraform-provider-iis/iis/agent")
+
 type Client struct {
 	Hostname string
 	Username string
 	Password string
+	ctx      context.Context
+}
+
+// WithContext returns a copy of the client whose commands are traced as children of ctx
+func (client Client) WithContext(ctx context.Context) *Client {
+	client.ctx = ctx
+	return &client
+}
+
+// Host returns the server the commands are executed against
+func (client Client) Host() string {
+	if len(client.Hostname) > 0 {
+		return client.Hostname
+	}
+
+	return "localhost"
+}
+
+func (client Client) context() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+
+	return client.ctx
 }
 
 func (client Client) Execute(script string) (*[]byte, error) {
+	ctx, span := tracer.Start(client.context(), "Client.Execute", trace.WithAttributes(
+		attribute.String("iis.host", client.Host()),
+		attribute.Bool("iis.remote", len(client.Hostname) > 0),
+		attribute.Int("iis.script_length", len(script)),
+	))
+	defer span.End()
+
+	bytes, err := client.execute(ctx, script)
+	if err != nil {
+		// The error echoes the failing lines of the script, which may hold passwords, so only its class is traced
+		class := errorClass(err)
+		span.RecordError(errors.New(class))
+		span.SetStatus(codes.Error, class)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("iis.output_length", len(*bytes)))
+	return bytes, nil
+}
+
+// maxErrorClassLength bounds the length of the errors recorded in the traces
+const maxErrorClassLength = 200
+
+// quotedLiterals matches the quoted literals of an error message, an unterminated one running to the end of the line
+var quotedLiterals = regexp.MustCompile(`'[^']*('|$)|"[^"]*("|$)`)
+
+// errorClass reduces the error to its first line, truncated and without its quoted literals
+func errorClass(err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+	line = strings.TrimSpace(quotedLiterals.ReplaceAllString(line, "''"))
+	if runes := []rune(line); len(runes) > maxErrorClassLength {
+		line = string(runes[:maxErrorClassLength])
+	}
+
+	return line
+}
+
+// phasesMarker prefixes the lines written by the script with the timings of its phases, which are removed from the output
+const phasesMarker = "##iis-phases;"
+
+// moduleImport matches the Import-Module statement leading a script, timed apart from the rest of the script
+var moduleImport = regexp.MustCompile(`^\s*Import-Module [\w.]+;`)
+
+func (client Client) execute(ctx context.Context, script string) (*[]byte, error) {
+	// The command runs as it always did, only wrapped in statements reporting the local start and end of the
+	// Invoke-Command and the durations, measured where the script block runs, of the module import and the script
 	var sb strings.Builder
-	sb.WriteString("Invoke-Command ")
+	sb.WriteString("$iisPhasesStarted = [DateTime]::UtcNow.Ticks; try { Invoke-Command ")
 	if len(client.Hostname) > 0 {
 		sb.WriteString(fmt.Sprintf("-ComputerName '%s' ", client.Hostname))
 	}
@@ -26,7 +107,24 @@ func (client Client) Execute(script string) (*[]byte, error) {
 	}
 
 	script = strings.ReplaceAll(script, `"`, `'`)
-	sb.WriteString(fmt.Sprintf("-ScriptBlock { param() %s } ", script))
+	imports := moduleImport.FindString(script)
+	timedImport := ""
+	if imports != "" {
+		timedImport = imports + " $iisPhasesImported = $iisPhasesWatch.Elapsed.Ticks;"
+	}
+
+	sb.WriteString(fmt.Sprintf(`-ScriptBlock { param()
+			$iisPhasesWatch = [System.Diagnostics.Stopwatch]::StartNew();
+			$iisPhasesImported = $null;
+			try {
+				%s
+				%s
+			} finally {
+				Write-Output ('%s{0};{1};{2}' -f $iisPhasesImported, $iisPhasesWatch.Elapsed.Ticks, 'remote')
+			}
+		} } finally {
+			Write-Output ('%s{0};{1};{2}' -f $iisPhasesStarted, [DateTime]::UtcNow.Ticks, 'local')
+		}`, timedImport, script[len(imports):], phasesMarker, phasesMarker))
 	command := append([]string{"-NoProfile", "-NonInteractive"}, sb.String())
 
 	ps, _ := exec.LookPath("powershell.exe")
@@ -35,7 +133,21 @@ func (client Client) Execute(script string) (*[]byte, error) {
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+
+	_, startSpan := tracer.Start(ctx, "powershell.start")
+	err := cmd.Start()
+	startSpan.End()
+	var output []byte
+	if err == nil {
+		invokeCtx, invokeSpan := tracer.Start(ctx, "powershell.invoke")
+		err = cmd.Wait()
+		invokeSpan.End()
+
+		var phases executionPhases
+		output, phases = splitPhases(stdout.Bytes())
+		phases.trace(invokeCtx, len(client.Hostname) > 0)
+	}
+
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		if stderr.Len() > 0 {
@@ -43,7 +155,7 @@ func (client Client) Execute(script string) (*[]byte, error) {
 			err = errors.New(stderr.String())
 		}
 
-		fmt.Printf("Stdout: %s\n", stdout.String())
+		fmt.Printf("Stdout: %s\n", output)
 		return nil, err
 	}
 
@@ -51,6 +163,90 @@ func (client Client) Execute(script string) (*[]byte, error) {
 		return nil, errors.New(stderr.String())
 	}
 
-	bytes := stdout.Bytes()
-	return &bytes, nil
+	return &output, nil
+}
+
+// executionPhases are the timings reported by the script, the instants being .NET ticks of the local clock and
+// the durations ticks measured where the script block runs
+type executionPhases struct {
+	started   int64
+	ended     int64
+	imported  int64
+	completed int64
+	explicit  bool
+	complete  bool
+}
+
+// splitPhases removes the timings of the phases from the output of the script
+func splitPhases(output []byte) ([]byte, executionPhases) {
+	var phases executionPhases
+	var localReported, remoteReported bool
+	var lines [][]byte
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		text := strings.TrimSpace(string(line))
+		if !strings.HasPrefix(text, phasesMarker) {
+			lines = append(lines, line)
+			continue
+		}
+
+		fields := strings.Split(strings.TrimPrefix(text, phasesMarker), ";")
+		if len(fields) != 3 {
+			continue
+		}
+
+		first, firstErr := strconv.ParseInt(fields[0], 10, 64)
+		second, secondErr := strconv.ParseInt(fields[1], 10, 64)
+		switch {
+		case fields[2] == "local" && firstErr == nil && secondErr == nil:
+			phases.started, phases.ended = first, second
+			localReported = true
+		case fields[2] == "remote" && secondErr == nil:
+			// The import is only reported for the scripts starting with an Import-Module statement
+			phases.imported, phases.completed, phases.explicit = first, second, firstErr == nil
+			remoteReported = true
+		}
+	}
+
+	phases.complete = localReported && remoteReported
+	return bytes.Join(lines, []byte("\n")), phases
+}
+
+// trace records the phases as child spans of ctx. The connection is what the Invoke-Command took on top of the
+// script block, so it includes the teardown of the remote session
+func (phases executionPhases) trace(ctx context.Context, remote bool) {
+	if !phases.complete {
+		return
+	}
+
+	started := ticksToTime(phases.started)
+	connected := ticksToTime(phases.ended).Add(-ticksToDuration(phases.completed))
+	if connected.Before(started) {
+		connected = started
+	}
+
+	if remote {
+		_, span := tracer.Start(ctx, "winrm.connect", trace.WithTimestamp(started))
+		span.End(trace.WithTimestamp(connected))
+	}
+
+	imported := connected
+	if phases.explicit {
+		imported = connected.Add(ticksToDuration(phases.imported))
+		_, span := tracer.Start(ctx, "powershell.import_module", trace.WithTimestamp(connected))
+		span.End(trace.WithTimestamp(imported))
+	}
+
+	_, span := tracer.Start(ctx, "powershell.command", trace.WithTimestamp(imported))
+	span.End(trace.WithTimestamp(connected.Add(ticksToDuration(phases.completed))))
+}
+
+// unixEpochTicks are the .NET ticks, of 100 nanoseconds since 0001-01-01, of the unix epoch
+const unixEpochTicks = 621355968000000000
+
+func ticksToTime(ticks int64) time.Time {
+	return time.Unix(0, 0).Add(ticksToDuration(ticks - unixEpochTicks))
+}
+
+func ticksToDuration(ticks int64) time.Duration {
+	return time.Duration(ticks) * 100
 }
@@ -0,0 +1,225 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	applicationHostPath             = "MACHINE/WEBROOT/APPHOST"
+	applicationPoolDefaults         = "system.applicationHost/applicationPools/applicationPoolDefaults"
+	siteDefaults                    = "system.applicationHost/sites/siteDefaults"
+	applicationDefaults             = "system.applicationHost/sites/applicationDefaults"
+	applicationPoolDefaultsSchedule = applicationPoolDefaults + "/recycling/periodicRestart/schedule"
+)
+
+// SiteDefaults are the siteDefaults, whose nil elements are left as they are on the host
+type SiteDefaults struct {
+	AutoStart                  bool                        `json:"serverAutoStart"`
+	Limits                     *Limits                     `json:"limits"`
+	LogFile                    *LogFile                    `json:"logFile"`
+	TraceFailedRequestsLogging *TraceFailedRequestsLogging `json:"traceFailedRequestsLogging"`
+}
+
+type ApplicationDefaults struct {
+	ApplicationPoolName      string `json:"applicationPool"`
+	EnabledProtocols         string `json:"enabledProtocols"`
+	PreloadEnabled           bool   `json:"preloadEnabled"`
+	ServiceAutoStartEnabled  bool   `json:"serviceAutoStartEnabled"`
+	ServiceAutoStartProvider string `json:"serviceAutoStartProvider"`
+}
+
+// StockApplicationPoolDefaults are the applicationPoolDefaults shipped with IIS 10
+var StockApplicationPoolDefaults = ApplicationPool{
+	AutoStart:             true,
+	StartMode:             "OnDemand",
+	PipelineMode:          "Integrated",
+	ManagedRuntimeVersion: "v4.0",
+	QueueLength:           1000,
+	ProcessModel: ProcessModel{
+		IdentityType:        "ApplicationPoolIdentity",
+		Password:            new(string),
+		LoadUserProfile:     true,
+		IdleTimeout:         TimeSpan(20 * time.Minute),
+		IdleTimeoutAction:   "Terminate",
+		MaxProcesses:        1,
+		PingingEnabled:      true,
+		PingInterval:        TimeSpan(30 * time.Second),
+		PingResponseTime:    TimeSpan(90 * time.Second),
+		StartupTimeLimit:    TimeSpan(90 * time.Second),
+		ShutdownTimeLimit:   TimeSpan(90 * time.Second),
+		LogIdleTimeoutEvent: true,
+		LogonType:           "LogonBatch",
+	},
+	CPU: CPU{
+		LimitInterval:           TimeSpan(5 * time.Minute),
+		Action:                  "NoAction",
+		ProcessorAffinityMask32: 4294967295,
+		ProcessorAffinityMask64: 4294967295,
+		NumaNodeAssignment:      "MostAvailableMemory",
+		NumaNodeAffinityMode:    "Soft",
+	},
+	Recycling: Recycling{
+		LogEvents: LogEvents{
+			Time:          true,
+			Memory:        true,
+			PrivateMemory: true,
+		},
+		PeriodicRestart: PeriodicRestart{
+			TimeInterval: TimeSpan(29 * time.Hour),
+		},
+	},
+	Failure: Failure{
+		RapidFailProtectionEnabled:    true,
+		RapidFailProtectionInterval:   TimeSpan(5 * time.Minute),
+		RapidFailProtectionMaxCrashes: 5,
+		LoadBalancerCapabilities:      "HttpLevel",
+	},
+}
+
+// StockSiteDefaults are the siteDefaults shipped with IIS 10
+var StockSiteDefaults = SiteDefaults{
+	AutoStart: true,
+	Limits: &Limits{
+		MaxBandwidth:      4294967295,
+		MaxConnections:    4294967295,
+		ConnectionTimeout: TimeSpan(2 * time.Minute),
+		MaxUrlSegments:    32,
+	},
+	LogFile: &LogFile{
+		Enabled:      true,
+		LogFormat:    "W3C",
+		Directory:    `%SystemDrive%\inetpub\logs\LogFiles`,
+		Period:       "Daily",
+		TruncateSize: 20971520,
+		LogExtFileFlags: FlagList{"Date", "Time", "ClientIP", "UserName", "ServerIP", "Method", "UriStem", "UriQuery", "HttpStatus",
+			"Win32Status", "TimeTaken", "ServerPort", "UserAgent", "Referer", "HttpSubStatus"},
+		LogTargetW3C: FlagList{"File"},
+	},
+	TraceFailedRequestsLogging: &TraceFailedRequestsLogging{
+		Directory:   `%SystemDrive%\inetpub\logs\FailedReqLogFiles`,
+		MaxLogFiles: 50,
+	},
+}
+
+// StockApplicationDefaults are the applicationDefaults shipped with IIS 10
+var StockApplicationDefaults = ApplicationDefaults{
+	ApplicationPoolName: "DefaultAppPool",
+	EnabledProtocols:    "http",
+}
+
+func (client Client) GetAppPoolDefaults() (*ApplicationPool, error) {
+	var response applicationPoolResponse
+	command := fmt.Sprintf(`(Get-IISServerManager).ApplicationPoolDefaults | %s | ConvertTo-Json -Compress`, appPoolProperties)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &response); err != nil {
+		return nil, err
+	}
+
+	return mapToApplicationPool(&response), nil
+}
+
+func (client Client) UpdateAppPoolDefaults(appPool ApplicationPool) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module WebAdministration;`)
+	sb.WriteString(setDefaultFunction(applicationPoolDefaults))
+	writeAppPoolProperties(&sb, "Set-Default", appPool)
+	sb.WriteString(fmt.Sprintf(`Clear-WebConfiguration -PSPath '%s' -Filter '%s';`, applicationHostPath, applicationPoolDefaultsSchedule))
+	for _, schedule := range appPool.Recycling.PeriodicRestart.Schedule {
+		sb.WriteString(fmt.Sprintf(`Add-WebConfigurationProperty -PSPath '%s' -Filter '%s' -Name '.' -Value @{value=%q};`, applicationHostPath, applicationPoolDefaultsSchedule, schedule))
+	}
+
+	_, err := client.Execute(sb.String())
+	return err
+}
+
+func (client Client) GetSiteDefaults() (*SiteDefaults, error) {
+	var response SiteDefaults
+	command := fmt.Sprintf(`
+		$defaults = Get-WebConfiguration -PSPath '%s' -Filter '%s';
+		[PSCustomObject]@{
+			serverAutoStart = $defaults.serverAutoStart;
+			limits = %s;
+			logFile = %s;
+			traceFailedRequestsLogging = %s;
+		} | ConvertTo-Json -Compress -Depth 4`, applicationHostPath, siteDefaults, limitsProjection("$defaults.limits"),
+		logFileProjection("$defaults.logFile"), tracingProjection("$defaults.traceFailedRequestsLogging"))
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (client Client) UpdateSiteDefaults(defaults SiteDefaults) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module WebAdministration;`)
+	sb.WriteString(setDefaultFunction(siteDefaults))
+	sb.WriteString(fmt.Sprintf(`Set-Default serverAutoStart %q;`, toPascalCase(defaults.AutoStart)))
+	if defaults.Limits != nil {
+		writeLimitsProperties(&sb, "Set-Default", *defaults.Limits)
+	}
+
+	if defaults.LogFile != nil {
+		writeLogFileProperties(&sb, "Set-Default", *defaults.LogFile)
+	}
+
+	if defaults.TraceFailedRequestsLogging != nil {
+		writeTracingProperties(&sb, "Set-Default", *defaults.TraceFailedRequestsLogging)
+	}
+
+	if _, err := client.Execute(sb.String()); err != nil {
+		return err
+	}
+
+	if defaults.LogFile == nil {
+		return nil
+	}
+
+	return client.updateCustomLogFields("", defaults.LogFile.CustomFields)
+}
+
+func (client Client) GetApplicationDefaults() (*ApplicationDefaults, error) {
+	var response ApplicationDefaults
+	command := fmt.Sprintf(`Get-WebConfiguration -PSPath '%s' -Filter '%s' | Select-Object applicationPool, enabledProtocols, preloadEnabled, serviceAutoStartEnabled, serviceAutoStartProvider | ConvertTo-Json -Compress`, applicationHostPath, applicationDefaults)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (client Client) UpdateApplicationDefaults(defaults ApplicationDefaults) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module WebAdministration;`)
+	sb.WriteString(setDefaultFunction(applicationDefaults))
+	sb.WriteString(fmt.Sprintf(`Set-Default applicationPool %s;`, toPowerShellString(defaults.ApplicationPoolName)))
+	sb.WriteString(fmt.Sprintf(`Set-Default enabledProtocols %q;`, defaults.EnabledProtocols))
+	sb.WriteString(fmt.Sprintf(`Set-Default preloadEnabled %q;`, toPascalCase(defaults.PreloadEnabled)))
+	sb.WriteString(fmt.Sprintf(`Set-Default serviceAutoStartEnabled %q;`, toPascalCase(defaults.ServiceAutoStartEnabled)))
+	sb.WriteString(fmt.Sprintf(`Set-Default serviceAutoStartProvider %s;`, toPowerShellString(defaults.ServiceAutoStartProvider)))
+	_, err := client.Execute(sb.String())
+	return err
+}
+
+// setDefaultFunction declares a Set-Default function writing an attribute of the given defaults element,
+// with the same arguments as Set-ItemProperty (including the common ones such as -ErrorAction)
+func setDefaultFunction(filter string) string {
+	return fmt.Sprintf(`function Set-Default { [CmdletBinding()] param($name, $value) Set-WebConfigurationProperty -PSPath '%s' -Filter '%s' -Name $name -Value $value };`, applicationHostPath, filter)
+}
@@ -2,56 +2,100 @@ package agent
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
-type DurationMinutes int
-type DurationSeconds int
-
-type timeSpan struct {
-	Ticks             float64 `json:"Ticks"`
-	Days              float64 `json:"Days"`
-	Hours             float64 `json:"Hours"`
-	Milliseconds      float64 `json:"Milliseconds"`
-	Minutes           float64 `json:"Minutes"`
-	Seconds           float64 `json:"Seconds"`
-	TotalDays         float64 `json:"TotalDays"`
-	TotalHours        float64 `json:"TotalHours"`
-	TotalMilliseconds float64 `json:"TotalMilliseconds"`
-	TotalMinutes      float64 `json:"TotalMinutes"`
-	TotalSeconds      float64 `json:"TotalSeconds"`
-}
+// TimeSpan is a duration written to IIS as a .NET TimeSpan ([-][d.]hh:mm:ss[.fffffff]), so that values
+// over 24 hours keep their days instead of wrapping around
+type TimeSpan time.Duration
 
-func (duration DurationMinutes) toTimeString() string {
-	var t time.Time
-	t = t.Add(time.Minute * time.Duration(duration))
-	return t.Format("15:04:05")
-}
+const ticksPerSecond = int64(time.Second / 100)
+
+var timeSpanRegex = regexp.MustCompile(`^(-)?(?:(\d+)\.)?(\d{1,2}):(\d{1,2})(?::(\d{1,2})(?:\.(\d{1,7}))?)?$`)
+
+func (timeSpan TimeSpan) String() string {
+	duration := time.Duration(timeSpan)
+	sign := ""
+	if duration < 0 {
+		sign = "-"
+		duration = -duration
+	}
+
+	ticks := int64(duration / 100)
+	days := ticks / (ticksPerSecond * 86400)
+	hours := ticks / (ticksPerSecond * 3600) % 24
+	minutes := ticks / (ticksPerSecond * 60) % 60
+	seconds := ticks / ticksPerSecond % 60
+	fraction := ticks % ticksPerSecond
 
-func (duration DurationSeconds) toTimeString() string {
-	var t time.Time
-	t = t.Add(time.Second * time.Duration(duration))
-	return t.Format("15:04:05")
+	var sb strings.Builder
+	sb.WriteString(sign)
+	if days > 0 {
+		sb.WriteString(fmt.Sprintf("%d.", days))
+	}
+
+	sb.WriteString(fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds))
+	if fraction > 0 {
+		sb.WriteString(fmt.Sprintf(".%07d", fraction))
+	}
+
+	return sb.String()
 }
 
-func (state *DurationMinutes) UnmarshalJSON(data []byte) error {
-	var rawValue timeSpan
-	err := json.Unmarshal(data, &rawValue)
-	if err != nil {
-		return err
+// ParseTimeSpan parses the .NET TimeSpan formats used by IIS: d, hh:mm, hh:mm:ss and d.hh:mm:ss[.fffffff]
+func ParseTimeSpan(value string) (TimeSpan, error) {
+	value = strings.TrimSpace(value)
+	if days, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return TimeSpan(time.Duration(days) * 24 * time.Hour), nil
 	}
 
-	*state = DurationMinutes(int(rawValue.TotalMinutes))
-	return nil
+	match := timeSpanRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("'%s' is not a valid TimeSpan, expected [-][d.]hh:mm[:ss[.fffffff]]", value)
+	}
+
+	days, _ := strconv.ParseInt("0"+match[2], 10, 64)
+	hours, _ := strconv.ParseInt(match[3], 10, 64)
+	minutes, _ := strconv.ParseInt(match[4], 10, 64)
+	seconds, _ := strconv.ParseInt("0"+match[5], 10, 64)
+	if hours > 23 || minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("'%s' is not a valid TimeSpan, hours, minutes or seconds are out of range", value)
+	}
+
+	fraction, _ := strconv.ParseInt((match[6] + "0000000")[:7], 10, 64)
+	ticks := (((days*24+hours)*60+minutes)*60+seconds)*ticksPerSecond + fraction
+	if match[1] == "-" {
+		ticks = -ticks
+	}
+
+	return TimeSpan(time.Duration(ticks) * 100), nil
 }
 
-func (state *DurationSeconds) UnmarshalJSON(data []byte) error {
-	var rawValue timeSpan
-	err := json.Unmarshal(data, &rawValue)
-	if err != nil {
+// UnmarshalJSON reads a System.TimeSpan serialized by ConvertTo-Json, or a TimeSpan string
+func (timeSpan *TimeSpan) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := ParseTimeSpan(text)
+		if err != nil {
+			return err
+		}
+
+		*timeSpan = parsed
+		return nil
+	}
+
+	var rawValue struct {
+		Ticks int64 `json:"Ticks"`
+	}
+
+	if err := json.Unmarshal(data, &rawValue); err != nil {
 		return err
 	}
 
-	*state = DurationSeconds(int(rawValue.TotalSeconds))
+	*timeSpan = TimeSpan(time.Duration(rawValue.Ticks) * 100)
 	return nil
 }
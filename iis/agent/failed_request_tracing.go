@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const traceFailedRequestsSection = "system.webServer/tracing/traceFailedRequests"
+
+// FailedRequestTracingRule is an entry of the traceFailedRequests section of a site, deciding which failed requests are traced
+type FailedRequestTracingRule struct {
+	WebSiteName string
+	Path        string
+	StatusCodes string
+	TimeTaken   TimeSpan
+	Verbosity   string
+	TraceAreas  []TraceArea
+}
+
+type TraceArea struct {
+	Provider  string
+	Areas     string
+	Verbosity string
+}
+
+type failedRequestTracingRuleResponse struct {
+	Path        string              `json:"path"`
+	StatusCodes string              `json:"statusCodes"`
+	TimeTaken   TimeSpan            `json:"timeTaken"`
+	Verbosity   int                 `json:"verbosity"`
+	TraceAreas  []traceAreaResponse `json:"traceAreas"`
+}
+
+type traceAreaResponse struct {
+	Provider  string `json:"provider"`
+	Areas     string `json:"areas"`
+	Verbosity int    `json:"verbosity"`
+}
+
+// The enumerations are read back as their numeric values, which are the indexes of their names
+var (
+	failureDefinitionVerbosities = []string{"Ignore", "CriticalError", "Error", "Warning"}
+	traceAreaVerbosities         = []string{"General", "CriticalError", "Error", "Warning", "Information", "Verbose"}
+)
+
+// tracingRulesCollection selects the traceFailedRequests collection of the site in $rules and the rule of the path in $rule
+func tracingRulesCollection(webSiteName string, path string) string {
+	return fmt.Sprintf(`
+		Import-Module IISAdministration;
+		$rules = Get-IISConfigSection -SectionPath '%s' -Location %s | Get-IISConfigCollection;
+		$path = %s;
+		$rule = $rules | Where-Object { $_['path'] -eq $path };`, traceFailedRequestsSection, toPowerShellString(webSiteName), toPowerShellString(path))
+}
+
+func (client Client) GetFailedRequestTracingRule(webSiteName string, path string) (*FailedRequestTracingRule, error) {
+	command := tracingRulesCollection(webSiteName, path) + `
+		if ($rule -ne $null) {
+			$failureDefinitions = $rule.GetChildElement('failureDefinitions');
+			[PSCustomObject]@{
+				path = $rule['path'];
+				statusCodes = $failureDefinitions['statusCodes'];
+				timeTaken = [string]$failureDefinitions['timeTaken'];
+				verbosity = [int]$failureDefinitions['verbosity'];
+				traceAreas = @($rule.GetCollection('traceAreas') | ForEach-Object {
+					[PSCustomObject]@{
+						provider = $_['provider'];
+						areas = $_['areas'];
+						verbosity = [int]$_['verbosity'];
+					}
+				});
+			} | ConvertTo-Json -Compress -Depth 4
+		}`
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*bytes) == 0 {
+		return nil, fmt.Errorf("failed request tracing rule '%s' could not be found on web site '%s'", path, webSiteName)
+	}
+
+	var response failedRequestTracingRuleResponse
+	if err = json.Unmarshal(*bytes, &response); err != nil {
+		return nil, err
+	}
+
+	traceAreas := []TraceArea{}
+	for _, traceArea := range response.TraceAreas {
+		traceAreas = append(traceAreas, TraceArea{
+			Provider:  traceArea.Provider,
+			Areas:     traceArea.Areas,
+			Verbosity: enumName(traceAreaVerbosities, traceArea.Verbosity),
+		})
+	}
+
+	return &FailedRequestTracingRule{
+		WebSiteName: webSiteName,
+		Path:        response.Path,
+		StatusCodes: response.StatusCodes,
+		TimeTaken:   response.TimeTaken,
+		Verbosity:   enumName(failureDefinitionVerbosities, response.Verbosity),
+		TraceAreas:  traceAreas,
+	}, nil
+}
+
+// PutFailedRequestTracingRule creates the rule of the path, or replaces it with its new definition
+func (client Client) PutFailedRequestTracingRule(rule FailedRequestTracingRule) error {
+	var sb strings.Builder
+	sb.WriteString(tracingRulesCollection(rule.WebSiteName, rule.Path))
+	sb.WriteString(fmt.Sprintf(`
+		Start-IISCommitDelay;
+		if ($rule -ne $null) { $rules.Remove($rule) };
+		$rule = $rules.CreateElement('add');
+		$rule['path'] = $path;
+		$failureDefinitions = $rule.GetChildElement('failureDefinitions');
+		$failureDefinitions['statusCodes'] = %s;
+		$failureDefinitions['timeTaken'] = [TimeSpan]::Parse(%q);
+		$failureDefinitions['verbosity'] = %q;
+		$traceAreas = $rule.GetCollection('traceAreas');`, toPowerShellString(rule.StatusCodes), rule.TimeTaken.String(), rule.Verbosity))
+
+	for _, traceArea := range rule.TraceAreas {
+		sb.WriteString(fmt.Sprintf(`
+		$traceArea = $traceAreas.CreateElement('add');
+		$traceArea['provider'] = %s;
+		$traceArea['areas'] = %s;
+		$traceArea['verbosity'] = %q;
+		[void]$traceAreas.Add($traceArea);`, toPowerShellString(traceArea.Provider), toPowerShellString(traceArea.Areas), traceArea.Verbosity))
+	}
+
+	sb.WriteString(`
+		[void]$rules.Add($rule);
+		Stop-IISCommitDelay;`)
+
+	_, err := client.Execute(sb.String())
+	return err
+}
+
+func (client Client) DeleteFailedRequestTracingRule(webSiteName string, path string) error {
+	command := tracingRulesCollection(webSiteName, path) + `
+		if ($rule -ne $null) {
+			Start-IISCommitDelay;
+			$rules.Remove($rule);
+			Stop-IISCommitDelay;
+		}`
+	_, err := client.Execute(command)
+	return err
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PhysicalPathProvisioning tells what to do with the directory of the physical path when writing a site or an application
+type PhysicalPathProvisioning struct {
+	Create      bool
+	Permissions []PathPermission
+	Revoked     []string
+}
+
+// PathPermission grants rights on the directory to an identity, inherited by its files and subdirectories
+type PathPermission struct {
+	Identity string
+	Rights   string
+}
+
+// PathRights are the rights which can be granted on a physical path
+var PathRights = []string{"Read", "ReadAndExecute", "Write", "Modify", "FullControl"}
+
+// pathRightsCodes are the icacls simple rights of the PathRights
+var pathRightsCodes = map[string]string{
+	"Read":           "R",
+	"ReadAndExecute": "RX",
+	"Write":          "W",
+	"Modify":         "M",
+	"FullControl":    "F",
+}
+
+// writePhysicalPathProvisioning provisions the directory of the physical path held in $path
+func writePhysicalPathProvisioning(sb *strings.Builder, provisioning PhysicalPathProvisioning) {
+	sb.WriteString(`
+		$directory = [Environment]::ExpandEnvironmentVariables($path);`)
+	if provisioning.Create {
+		sb.WriteString(`
+		if (!(Test-Path -LiteralPath $directory)) { [void](New-Item -ItemType Directory -Path $directory) };`)
+	}
+
+	for _, identity := range provisioning.Revoked {
+		sb.WriteString(fmt.Sprintf(`
+		icacls $directory /remove:g %s | Out-Null;
+		if ($LASTEXITCODE -ne 0) { throw ('failed to revoke the permissions of {0} on {1}' -f %[1]s, $directory) };`, toPowerShellString(identity)))
+	}
+
+	// The inheritance flags make the files and subdirectories inherit the rights, without rewriting each of them like /T does
+	for _, permission := range provisioning.Permissions {
+		sb.WriteString(fmt.Sprintf(`
+		icacls $directory /grant:r ('{0}:(OI)(CI){1}' -f %s, '%s') | Out-Null;
+		if ($LASTEXITCODE -ne 0) { throw ('failed to grant {0} to {1} on {2}' -f '%[2]s', %[1]s, $directory) };`, toPowerShellString(permission.Identity), pathRightsCodes[permission.Rights]))
+	}
+}
+
+// RemovePhysicalPath deletes the directory of the physical path, only when it is empty unless removeContent is set
+func (client Client) RemovePhysicalPath(physicalPath string, removeContent bool) error {
+	condition := `-not (Get-ChildItem -LiteralPath $directory -Force | Select-Object -First 1)`
+	if removeContent {
+		condition = `$true`
+	}
+
+	command := fmt.Sprintf(`
+		$directory = [Environment]::ExpandEnvironmentVariables(%s);
+		if ((Test-Path -LiteralPath $directory) -and (%s)) {
+			Remove-Item -LiteralPath $directory -Recurse -Force;
+		}`, toPowerShellString(strings.ReplaceAll(physicalPath, "/", `\`)), condition)
+	_, err := client.Execute(command)
+	return err
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type ServerVersion struct {
+	Major int `json:"MajorVersion"`
+	Minor int `json:"MinorVersion"`
+	// WindowsBuild tells apart the IIS 10 releases, which all report version 10.0
+	WindowsBuild int `json:"WindowsBuild"`
+}
+
+func (client Client) GetServerVersion() (*ServerVersion, error) {
+	var response ServerVersion
+	command := `
+		$inetStp = Get-ItemProperty -Path 'HKLM:\SOFTWARE\Microsoft\InetStp';
+		[PSCustomObject]@{
+			MajorVersion = $inetStp.MajorVersion;
+			MinorVersion = $inetStp.MinorVersion;
+			WindowsBuild = [System.Environment]::OSVersion.Version.Build;
+		} | ConvertTo-Json -Compress`
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// AtLeast reports whether the host runs IIS major.minor or later
+func (version ServerVersion) AtLeast(major int, minor int) bool {
+	return version.Major > major || (version.Major == major && version.Minor >= minor)
+}
+
+// SupportsHsts reports whether the sites have the native hsts element, added by IIS 10 on Windows Server version 1709
+func (version ServerVersion) SupportsHsts() bool {
+	return version.Major > 10 || (version.Major == 10 && version.WindowsBuild >= 16299)
+}
+
+func (version ServerVersion) String() string {
+	return fmt.Sprintf("IIS %d.%d (Windows build %d)", version.Major, version.Minor, version.WindowsBuild)
+}
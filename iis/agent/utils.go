@@ -1,5 +1,10 @@
 package agent
 
+import (
+	"encoding/base64"
+	"fmt"
+)
+
 func toPascalCase(value bool) string {
 	bVal := "False"
 	if value {
@@ -8,3 +13,19 @@ func toPascalCase(value bool) string {
 
 	return bVal
 }
+
+// toPowerShellString returns a PowerShell expression evaluating to value, so that credentials
+// containing quotes, backslashes or dollar signs survive the script quoting untouched
+func toPowerShellString(value string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(value))
+	return fmt.Sprintf(`([System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s')))`, encoded)
+}
+
+// enumName returns the name of an enumeration read back as its numeric value, the names being listed in the order of their values
+func enumName(names []string, value int) string {
+	if value < 0 || value >= len(names) {
+		return fmt.Sprint(value)
+	}
+
+	return names[value]
+}
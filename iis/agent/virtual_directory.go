@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogonMethods are the logon types used to impersonate the physical path credentials of a virtual directory
+var LogonMethods = []string{"Interactive", "Batch", "Network", "ClearText"}
+
+// rootVirtualDirectoryFilter selects the root virtual directory of the application, which holds its physical path and credentials
+func rootVirtualDirectoryFilter(webSiteName string, applicationPath string) string {
+	return fmt.Sprintf("system.applicationHost/sites/site[@name='%s']/application[@path='%s']/virtualDirectory[@path='/']",
+		strings.ReplaceAll(webSiteName, "'", "''"), strings.ReplaceAll(applicationPath, "'", "''"))
+}
+
+func writeLogonMethod(sb *strings.Builder, webSiteName string, applicationPath string, logonMethod string) {
+	writeRootVirtualDirectoryProperty(sb, webSiteName, applicationPath, "logonMethod", fmt.Sprintf("%q", logonMethod))
+}
+
+// writeCredentials sets the user impersonated to access the physical path of the application, along with its password
+// unless it is nil, the password being never read back
+func writeCredentials(sb *strings.Builder, webSiteName string, applicationPath string, username string, password *string) {
+	writeRootVirtualDirectoryProperty(sb, webSiteName, applicationPath, "userName", toPowerShellString(username))
+	if password != nil {
+		writeRootVirtualDirectoryProperty(sb, webSiteName, applicationPath, "password", toPowerShellString(*password))
+	}
+}
+
+func writeRootVirtualDirectoryProperty(sb *strings.Builder, webSiteName string, applicationPath string, name string, value string) {
+	sb.WriteString(fmt.Sprintf(`Set-WebConfigurationProperty -PSPath 'MACHINE/WEBROOT/APPHOST' -Filter %s -Name %s -Value %s;`,
+		toPowerShellString(rootVirtualDirectoryFilter(webSiteName, applicationPath)), name, value))
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -12,12 +13,27 @@ type WebApplication struct {
 	Path                string `json:"path"`
 	PhysicalPath        string `json:"PhysicalPath"`
 	ApplicationPoolName string `json:"applicationPool"`
+	LogonMethod         string `json:"logonMethod"`
+	Username            string `json:"username"`
+	Password            *string
 	Site                string
+	ItemXPath           string `json:"ItemXPath"`
+
+	PhysicalPathProvisioning PhysicalPathProvisioning
 }
 
+// webApplicationProjection adds the logon method and the user of the root virtual directory to the applications piped into it
+const webApplicationProjection = `ForEach-Object {
+		$root = $_.Collection | Where-Object { $_.path -eq '/' };
+		$_ | Add-Member -NotePropertyName logonMethod -NotePropertyValue ([string]$root.logonMethod) -PassThru |
+			Add-Member -NotePropertyName username -NotePropertyValue ([string]$root.userName) -PassThru
+	}`
+
+var siteNameXPathRegex = regexp.MustCompile(`/site\[@name='((?:[^']|'')*)'`)
+
 func (client Client) GetWebApplication(site string, name string) (*WebApplication, error) {
 	var response WebApplication
-	command := fmt.Sprintf("Get-WebApplication -Site '%s' -Name '%s' | ConvertTo-Json -Compress", site, name)
+	command := fmt.Sprintf("Get-WebApplication -Site '%s' -Name '%s' | %s | ConvertTo-Json -Compress", site, name, webApplicationProjection)
 	bytes, err := client.Execute(command)
 	if err != nil {
 		return nil, err
@@ -34,21 +50,51 @@ func (client Client) GetWebApplication(site string, name string) (*WebApplicatio
 	return &response, nil
 }
 
+func (client Client) ListWebApplications() ([]WebApplication, error) {
+	var responses []WebApplication
+	command := fmt.Sprintf(`'[' + ((Get-WebApplication | %s | ForEach-Object { $_ | ConvertTo-Json -Compress }) -join ',') + ']'`, webApplicationProjection)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &responses); err != nil {
+		return nil, err
+	}
+
+	webApplications := []WebApplication{}
+	for _, webApplication := range responses {
+		// The site is only known through the configuration path of the application
+		if match := siteNameXPathRegex.FindStringSubmatch(webApplication.ItemXPath); match != nil {
+			webApplication.Site = strings.ReplaceAll(match[1], "''", "'")
+		}
+
+		webApplication.Name = strings.TrimPrefix(webApplication.Path, "/")
+		webApplication.Id = fmt.Sprintf("%s_%s", webApplication.Site, webApplication.Name)
+		webApplications = append(webApplications, webApplication)
+	}
+
+	return webApplications, nil
+}
+
 func (client Client) CreateWebApplication(webApplication WebApplication) (*WebApplication, error) {
+	var sb strings.Builder
 	physicalPath := strings.ReplaceAll(webApplication.PhysicalPath, "/", `\`)
-	command := fmt.Sprintf(`
-		$path='%v'
-		if (!(Test-Path $path)){
-            New-Item -ItemType Directory -Path $path;
-			icacls $path /grant "IIS_IUSRS:(OI)(CI)F" /T
-        }
+	sb.WriteString(fmt.Sprintf(`
+		$path='%v';`, physicalPath))
+	writePhysicalPathProvisioning(&sb, webApplication.PhysicalPathProvisioning)
+	sb.WriteString(fmt.Sprintf(`
 		New-WebApplication -Site %q -ApplicationPool %q -Name %q -PhysicalPath $path;
-	`, physicalPath,
-		webApplication.Site,
+	`, webApplication.Site,
 		webApplication.ApplicationPoolName,
-		webApplication.Name)
+		webApplication.Name))
 
-	_, err := client.Execute(command)
+	if webApplication.LogonMethod != "" {
+		writeLogonMethod(&sb, webApplication.Site, "/"+webApplication.Name, webApplication.LogonMethod)
+	}
+
+	writeCredentials(&sb, webApplication.Site, "/"+webApplication.Name, webApplication.Username, webApplication.Password)
+	_, err := client.Execute(sb.String())
 	if err != nil {
 		return nil, err
 	}
@@ -86,17 +132,18 @@ func (client Client) updateWebApplication(webApplication WebApplication) error {
 	physicalPath := strings.ReplaceAll(webApplication.PhysicalPath, "/", `\`)
 	sb.WriteString(`Import-Module WebAdministration;`)
 	sb.WriteString(fmt.Sprintf(`
-		$path='%v'
-		if (!(Test-Path $path)){
-            New-Item -ItemType Directory -Path $path;
-			icacls $path /grant "IIS_IUSRS:(OI)(CI)F" /T
-        }
-	`, physicalPath))
+		$path='%v';`, physicalPath))
+	writePhysicalPathProvisioning(&sb, webApplication.PhysicalPathProvisioning)
 
 	setProp := fmt.Sprintf(`Set-ItemProperty -Path 'IIS:\Sites\%s\%s'`, webApplication.Site, webApplication.Name)
 	sb.WriteString(fmt.Sprintf(`%s applicationPool %q;`, setProp, webApplication.ApplicationPoolName))
 	sb.WriteString(fmt.Sprintf(`%s physicalPath $path;`, setProp))
+	if webApplication.LogonMethod != "" {
+		writeLogonMethod(&sb, webApplication.Site, "/"+webApplication.Name, webApplication.LogonMethod)
+	}
 
+	// The password is never read back, so the rollbacks, which have none, leave it alone
+	writeCredentials(&sb, webApplication.Site, "/"+webApplication.Name, webApplication.Username, webApplication.Password)
 	command := sb.String()
 	_, err := client.Execute(command)
 	if err != nil {
@@ -3,6 +3,7 @@ package agent
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -11,19 +12,55 @@ type WebSite struct {
 	Id                         string `json:"id"`
 	Name                       string `json:"name"`
 	State                      string `json:"state"`
+	AutoStart                  bool
 	PhysicalPath               string `json:"physicalPath"`
 	Username                   string `json:"username"`
-	Password                   string `json:"password"`
+	Password                   *string
+	LogonMethod                string
 	Bindings                   []Binding
-	TraceFailedRequestsLogging TraceFailedRequestsLogging `json:"traceFailedRequestsLogging"`
+	EnabledProtocols           []string
+	Limits                     *Limits
+	LogFile                    *LogFile
+	Hsts                       *Hsts
+	TraceFailedRequestsLogging *TraceFailedRequestsLogging `json:"traceFailedRequestsLogging"`
 	ApplicationPoolName        string
+	PhysicalPathProvisioning   PhysicalPathProvisioning
 }
 
 type Binding struct {
-	Protocol   string
-	HostHeader string
-	Ip         string
-	Port       int
+	Protocol              string
+	HostHeader            string
+	Ip                    string
+	Port                  int
+	Information           string
+	CertificateThumbprint string
+	CertificateStoreName  string
+	SslFlags              []string
+}
+
+// ipProtocols are the protocols whose binding information is IP:port:host, the WAS protocols
+// (net.tcp, net.pipe, net.msmq and msmq.formatname) having their own format such as 808:* or *
+var ipProtocols = []string{"http", "https", "ftp"}
+
+// sslFlags are the values of the sslFlags attribute of the bindings, by name
+var sslFlags = []struct {
+	name  string
+	value int
+}{
+	{"Sni", 1},
+	{"CentralCertStore", 2},
+	{"DisableHTTP2", 4},
+	{"DisableOCSPStapling", 8},
+	{"DisableQUIC", 16},
+	{"DisableTLS13", 32},
+	{"DisableLegacyTLS", 64},
+}
+
+type Limits struct {
+	MaxBandwidth      int64    `json:"maxBandwidth"`
+	MaxConnections    int64    `json:"maxConnections"`
+	ConnectionTimeout TimeSpan `json:"connectionTimeout"`
+	MaxUrlSegments    int      `json:"maxUrlSegments"`
 }
 
 type TraceFailedRequestsLogging struct {
@@ -32,46 +69,194 @@ type TraceFailedRequestsLogging struct {
 	MaxLogFiles int    `json:"maxLogFiles"`
 }
 
-type Hsts struct {
-	Enabled             bool `json:"enabled"`
-	MaxAge              int  `json:"max-age"`
-	IncludeSubDomains   bool `json:"includeSubDomains"`
-	Preload             bool `json:"preload"`
-	RedirectHttpToHttps bool `json:"redirectHttpToHttps"`
+type LogFile struct {
+	Enabled           bool             `json:"enabled"`
+	LogFormat         string           `json:"logFormat"`
+	Directory         string           `json:"directory"`
+	Period            string           `json:"period"`
+	TruncateSize      int64            `json:"truncateSize"`
+	LocalTimeRollover bool             `json:"localTimeRollover"`
+	LogExtFileFlags   FlagList         `json:"logExtFileFlags"`
+	LogTargetW3C      FlagList         `json:"logTargetW3C"`
+	CustomFields      []LogCustomField `json:"customFields"`
+}
+
+// LogCustomField is a request header, response header or server variable appended to the W3C log entries (IIS 8.5+)
+type LogCustomField struct {
+	LogFieldName string `json:"logFieldName"`
+	SourceName   string `json:"sourceName"`
+	SourceType   string `json:"sourceType"`
 }
 
-type bindingInformationResponse struct {
-	Ip         string
-	Port       int
-	HostHeader string
+// FlagList is a flags attribute, such as logExtFileFlags, read and written as the comma separated names of its flags
+type FlagList []string
+
+type Hsts struct {
+	Enabled             bool  `json:"enabled"`
+	MaxAge              int64 `json:"max-age"`
+	IncludeSubDomains   bool  `json:"includeSubDomains"`
+	Preload             bool  `json:"preload"`
+	RedirectHttpToHttps bool  `json:"redirectHttpToHttps"`
 }
 
 type websiteResponse struct {
-	Id              int             `json:"id"`
-	Name            string          `json:"name"`
-	ServerAutoStart bool            `json:"serverAutoStart"`
-	State           string          `json:"state"`
-	PhysicalPath    string          `json:"physicalPath"`
-	Username        string          `json:"username"`
-	Password        string          `json:"password"`
-	Bindings        bindingResponse `json:"bindings"`
-	ApplicationPool string          `json:"applicationPool"`
+	Id               int               `json:"id"`
+	Name             string            `json:"name"`
+	ServerAutoStart  bool              `json:"serverAutoStart"`
+	State            string            `json:"state"`
+	PhysicalPath     string            `json:"physicalPath"`
+	Username         string            `json:"username"`
+	LogonMethod      string            `json:"logonMethod"`
+	Bindings         []bindingResponse `json:"bindings"`
+	EnabledProtocols string            `json:"enabledProtocols"`
+	ApplicationPool  string            `json:"applicationPool"`
+	Limits           Limits            `json:"limits"`
+	LogFile          LogFile           `json:"logFile"`
+	Hsts             Hsts              `json:"hsts"`
 
 	TraceFailedRequestsLogging TraceFailedRequestsLogging `json:"traceFailedRequestsLogging"`
 }
 
 type bindingResponse struct {
-	Collection []bindingCollectionItemResponse
+	Protocol             string           `json:"protocol"`
+	BindingInformation   string           `json:"bindingInformation"`
+	SslFlags             sslFlagsResponse `json:"sslFlags"`
+	CertificateHash      string           `json:"certificateHash"`
+	CertificateStoreName string           `json:"certificateStoreName"`
 }
 
-type bindingCollectionItemResponse struct {
-	Protocol           string                     `json:"protocol"`
-	BindingInformation bindingInformationResponse `json:"bindingInformation"`
+type sslFlagsResponse int
+
+// webSiteProjection turns the sites piped into it into plain objects, so that their nested elements survive ConvertTo-Json
+var webSiteProjection = fmt.Sprintf(`ForEach-Object {
+		[PSCustomObject]@{
+			id = $_.id;
+			name = $_.name;
+			serverAutoStart = $_.serverAutoStart;
+			state = $_.state;
+			physicalPath = $_.physicalPath;
+			username = $_.userName;
+			logonMethod = [string](($_.Collection | Where-Object { $_.path -eq '/' }).Collection | Where-Object { $_.path -eq '/' }).logonMethod;
+			applicationPool = $_.applicationPool;
+			enabledProtocols = $_.enabledProtocols;
+			bindings = @($_.bindings.Collection | ForEach-Object {
+				[PSCustomObject]@{
+					protocol = $_.protocol;
+					bindingInformation = $_.bindingInformation;
+					sslFlags = $_.sslFlags;
+					certificateHash = [string]$_.certificateHash;
+					certificateStoreName = [string]$_.certificateStoreName;
+				}
+			});
+			limits = %s;
+			logFile = %s;
+			hsts = [PSCustomObject]@{
+				enabled = [bool]$_.hsts.enabled;
+				'max-age' = [int64]$_.hsts.'max-age';
+				includeSubDomains = [bool]$_.hsts.includeSubDomains;
+				preload = [bool]$_.hsts.preload;
+				redirectHttpToHttps = [bool]$_.hsts.redirectHttpToHttps;
+			};
+			traceFailedRequestsLogging = %s;
+		}
+	}`, limitsProjection("$_.limits"), logFileProjection("$_.logFile"), tracingProjection("$_.traceFailedRequestsLogging"))
+
+// limitsProjection returns the plain object written by ConvertTo-Json for the limits element of a site or of the siteDefaults
+func limitsProjection(element string) string {
+	return fmt.Sprintf(`[PSCustomObject]@{
+				maxBandwidth = %[1]s.maxBandwidth;
+				maxConnections = %[1]s.maxConnections;
+				connectionTimeout = [string]%[1]s.connectionTimeout;
+				maxUrlSegments = %[1]s.maxUrlSegments;
+			}`, element)
+}
+
+// tracingProjection returns the plain object written by ConvertTo-Json for the traceFailedRequestsLogging element of a site or of the siteDefaults
+func tracingProjection(element string) string {
+	return fmt.Sprintf(`[PSCustomObject]@{
+				enabled = %[1]s.enabled;
+				directory = %[1]s.directory;
+				maxLogFiles = %[1]s.maxLogFiles;
+			}`, element)
+}
+
+// writeLimitsProperties writes the limits of a site or of the siteDefaults, each command prefixed by setProp
+func writeLimitsProperties(sb *strings.Builder, setProp string, limits Limits) {
+	sb.WriteString(fmt.Sprintf(`%s limits.maxBandwidth %d;`, setProp, limits.MaxBandwidth))
+	sb.WriteString(fmt.Sprintf(`%s limits.maxConnections %d;`, setProp, limits.MaxConnections))
+	sb.WriteString(fmt.Sprintf(`%s limits.connectionTimeout %q;`, setProp, limits.ConnectionTimeout.String()))
+	sb.WriteString(fmt.Sprintf(`%s limits.maxUrlSegments %d;`, setProp, limits.MaxUrlSegments))
+}
+
+// writeTracingProperties writes the failed request tracing of a site or of the siteDefaults, each command prefixed by setProp
+func writeTracingProperties(sb *strings.Builder, setProp string, tracing TraceFailedRequestsLogging) {
+	sb.WriteString(fmt.Sprintf(`%s traceFailedRequestsLogging.enabled %q;`, setProp, toPascalCase(tracing.Enabled)))
+	sb.WriteString(fmt.Sprintf(`%s traceFailedRequestsLogging.directory %s;`, setProp, toPowerShellString(tracing.Directory)))
+	sb.WriteString(fmt.Sprintf(`%s traceFailedRequestsLogging.maxLogFiles %d;`, setProp, tracing.MaxLogFiles))
+}
+
+// logFileProjection returns the plain object written by ConvertTo-Json for the logFile element of a site or of the siteDefaults
+func logFileProjection(element string) string {
+	return fmt.Sprintf(`[PSCustomObject]@{
+				enabled = %[1]s.enabled;
+				logFormat = [string]%[1]s.logFormat;
+				directory = %[1]s.directory;
+				period = [string]%[1]s.period;
+				truncateSize = %[1]s.truncateSize;
+				localTimeRollover = %[1]s.localTimeRollover;
+				logExtFileFlags = [string]%[1]s.logExtFileFlags;
+				logTargetW3C = [string]%[1]s.logTargetW3C;
+				customFields = @(%[1]s.customFields.Collection | ForEach-Object {
+					[PSCustomObject]@{
+						logFieldName = $_.logFieldName;
+						sourceName = $_.sourceName;
+						sourceType = [string]$_.sourceType;
+					}
+				});
+			}`, element)
+}
+
+// writeLogFileProperties writes the logFile element of a site or of the siteDefaults through setProp
+func writeLogFileProperties(sb *strings.Builder, setProp string, logFile LogFile) {
+	sb.WriteString(fmt.Sprintf(`%s logFile.enabled %q;`, setProp, toPascalCase(logFile.Enabled)))
+	sb.WriteString(fmt.Sprintf(`%s logFile.logFormat %q;`, setProp, logFile.LogFormat))
+	sb.WriteString(fmt.Sprintf(`%s logFile.directory %s;`, setProp, toPowerShellString(logFile.Directory)))
+	sb.WriteString(fmt.Sprintf(`%s logFile.period %q;`, setProp, logFile.Period))
+	sb.WriteString(fmt.Sprintf(`%s logFile.truncateSize %d;`, setProp, logFile.TruncateSize))
+	sb.WriteString(fmt.Sprintf(`%s logFile.localTimeRollover %q;`, setProp, toPascalCase(logFile.LocalTimeRollover)))
+	if len(logFile.LogExtFileFlags) > 0 {
+		sb.WriteString(fmt.Sprintf(`%s logFile.logExtFileFlags %q;`, setProp, logFile.LogExtFileFlags.String()))
+	}
+
+	if len(logFile.LogTargetW3C) > 0 {
+		writeOptionalProperty(sb, setProp, "logFile.logTargetW3C", fmt.Sprintf("%q", logFile.LogTargetW3C.String()), logFile.LogTargetW3C.String() == "File")
+	}
+}
+
+// updateCustomLogFields replaces the custom fields of the logFile element of a site, or of the siteDefaults when siteName is empty
+func (client Client) updateCustomLogFields(siteName string, fields []LogCustomField) error {
+	var sb strings.Builder
+	sb.WriteString(`Import-Module IISAdministration; $manager = Get-IISServerManager;`)
+	if siteName == "" {
+		sb.WriteString(`$logFile = $manager.SiteDefaults.LogFile;`)
+	} else {
+		sb.WriteString(fmt.Sprintf(`$logFile = $manager.Sites[%s].LogFile;`, toPowerShellString(siteName)))
+	}
+
+	// The custom fields only exist since IIS 8.5, so older hosts are left alone unless fields are configured
+	sb.WriteString(fmt.Sprintf(`try { $fields = $logFile.CustomLogFields; $fields.Clear() } catch { if ($%t) { throw }; return };`, len(fields) > 0))
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf(`$fields.Add(%s, %s, %q) | Out-Null;`, toPowerShellString(field.LogFieldName), toPowerShellString(field.SourceName), field.SourceType))
+	}
+
+	sb.WriteString(`$manager.CommitChanges();`)
+	_, err := client.Execute(sb.String())
+	return err
 }
 
 func (client Client) GetWebSite(name string) (*WebSite, error) {
 	var response websiteResponse
-	command := fmt.Sprintf("Get-Website -Name '%s' | ConvertTo-Json -Compress", name)
+	command := fmt.Sprintf("Get-Website -Name '%s' | %s | ConvertTo-Json -Compress -Depth 4", name, webSiteProjection)
 	bytes, err := client.Execute(command)
 	if err != nil {
 		return nil, err
@@ -86,21 +271,42 @@ func (client Client) GetWebSite(name string) (*WebSite, error) {
 	return webSite, nil
 }
 
+func (client Client) ListWebSites() ([]WebSite, error) {
+	var responses []websiteResponse
+	command := fmt.Sprintf("ConvertTo-Json -InputObject @(Get-Website | %s) -Compress -Depth 4", webSiteProjection)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(*bytes, &responses); err != nil {
+		return nil, err
+	}
+
+	webSites := []WebSite{}
+	for _, response := range responses {
+		webSites = append(webSites, *mapWebSite(&response))
+	}
+
+	return webSites, nil
+}
+
 func (client Client) CreateWebSite(webSite WebSite) (*WebSite, error) {
+	var sb strings.Builder
 	physicalPath := strings.ReplaceAll(webSite.PhysicalPath, "/", `\`)
-	command := fmt.Sprintf(`
-		$path='%v'
-		if (!(Test-Path $path)){
-            New-Item -ItemType Directory -Path $path;
-			icacls $path /grant "IIS_IUSRS:(OI)(CI)F" /T
-        }
-		New-Website -Name %q -PhysicalPath $path;
-	`, physicalPath, webSite.Name)
-	_, err := client.Execute(command)
+	sb.WriteString(fmt.Sprintf(`
+		$path='%v';`, physicalPath))
+	writePhysicalPathProvisioning(&sb, webSite.PhysicalPathProvisioning)
+	sb.WriteString(fmt.Sprintf(`
+		New-Website -Name %q -PhysicalPath $path %s;`, webSite.Name, siteIdParameter(webSite.Id)))
+	_, err := client.Execute(sb.String())
 	if err != nil {
 		return nil, err
 	}
 
+	// The physical path is already provisioned and the id already given to New-Website
+	webSite.PhysicalPathProvisioning = PhysicalPathProvisioning{}
+	webSite.Id = ""
 	err = client.updateWebSite(webSite)
 	if err != nil {
 		return nil, client.DeleteWebSite(webSite.Name)
@@ -115,6 +321,15 @@ func (client Client) UpdateWebSite(webSite WebSite) error {
 		return err
 	}
 
+	// The id is only written when it changes, as IIS restarts the site, and the rollback only restores a changed id
+	if webSite.Id == existingWebSite.Id {
+		webSite.Id = ""
+	}
+
+	if webSite.Id == "" {
+		existingWebSite.Id = ""
+	}
+
 	err = client.updateWebSite(webSite)
 	if err != nil {
 		client.updateWebSite(*existingWebSite)
@@ -129,25 +344,67 @@ func (client Client) updateWebSite(webSite WebSite) error {
 	physicalPath := strings.ReplaceAll(webSite.PhysicalPath, "/", `\`)
 	sb.WriteString(`Import-Module WebAdministration;`)
 	sb.WriteString(fmt.Sprintf(`
-		$path='%v'
-		if (!(Test-Path $path)){
-            New-Item -ItemType Directory -Path $path;
-			icacls $path /grant "IIS_IUSRS:(OI)(CI)F" /T
-        }
-	`, physicalPath))
+		$path='%v';`, physicalPath))
+	writePhysicalPathProvisioning(&sb, webSite.PhysicalPathProvisioning)
 
 	setProp := fmt.Sprintf(`Set-ItemProperty -Path 'IIS:\Sites\%s'`, webSite.Name)
+	if webSite.Id != "" {
+		// IIS restarts the site when its id changes, the log and trace directories following the new id
+		sb.WriteString(fmt.Sprintf(`%s id %s;`, setProp, webSite.Id))
+	}
+
 	sb.WriteString(fmt.Sprintf(`%s applicationPool %q;`, setProp, webSite.ApplicationPoolName))
+	sb.WriteString(fmt.Sprintf(`%s serverAutoStart %q;`, setProp, toPascalCase(webSite.AutoStart)))
 	sb.WriteString(fmt.Sprintf(`%s physicalPath %v;`, setProp, physicalPath))
-	sb.WriteString(fmt.Sprintf(`%s userName %q;`, setProp, webSite.Username))
-	sb.WriteString(fmt.Sprintf(`%s password %q;`, setProp, webSite.Password))
+	sb.WriteString(fmt.Sprintf(`%s userName %s;`, setProp, toPowerShellString(webSite.Username)))
+	if webSite.Password != nil {
+		// The password is never read back, so it is only written along with a new one or a new user
+		sb.WriteString(fmt.Sprintf(`%s password %s;`, setProp, toPowerShellString(*webSite.Password)))
+	}
+
+	if webSite.LogonMethod != "" {
+		writeLogonMethod(&sb, webSite.Name, "/", webSite.LogonMethod)
+	}
+
+	if webSite.Limits != nil {
+		writeLimitsProperties(&sb, setProp, *webSite.Limits)
+	}
+
+	if webSite.LogFile != nil {
+		writeLogFileProperties(&sb, setProp, *webSite.LogFile)
+	}
+
+	if webSite.Hsts != nil {
+		sb.WriteString(fmt.Sprintf(`%s hsts.enabled %q;`, setProp, toPascalCase(webSite.Hsts.Enabled)))
+		sb.WriteString(fmt.Sprintf(`%s hsts.max-age %d;`, setProp, webSite.Hsts.MaxAge))
+		sb.WriteString(fmt.Sprintf(`%s hsts.includeSubDomains %q;`, setProp, toPascalCase(webSite.Hsts.IncludeSubDomains)))
+		sb.WriteString(fmt.Sprintf(`%s hsts.preload %q;`, setProp, toPascalCase(webSite.Hsts.Preload)))
+		sb.WriteString(fmt.Sprintf(`%s hsts.redirectHttpToHttps %q;`, setProp, toPascalCase(webSite.Hsts.RedirectHttpToHttps)))
+	}
+
+	if webSite.TraceFailedRequestsLogging != nil {
+		writeTracingProperties(&sb, setProp, *webSite.TraceFailedRequestsLogging)
+	}
+
+	if len(webSite.EnabledProtocols) > 0 {
+		sb.WriteString(fmt.Sprintf(`%s enabledProtocols %q;`, setProp, strings.Join(webSite.EnabledProtocols, ",")))
+	}
+
 	sb.WriteString(fmt.Sprintf(` 
 		$siteName=%q;
 		Get-WebBinding -Name $siteName | ForEach-Object { Remove-WebBinding -Name $siteName -BindingInformation $_.bindingInformation -Protocol $_.protocol; };`, webSite.Name))
 
 	for _, binding := range webSite.Bindings {
-		str := fmt.Sprintf("New-WebBinding -Name %q -IPAddress %q -Port %d -HostHeader %q -Protocol %q;", webSite.Name, binding.Ip, binding.Port, binding.HostHeader, binding.Protocol)
+		// New-WebBinding only builds IPv4 binding information, so the bindings are added to the collection as they are
+		str := fmt.Sprintf(`New-ItemProperty -Path 'IIS:\Sites\%s' -Name bindings -Value @{protocol=%q;bindingInformation=%q;sslFlags=%d};`, webSite.Name, binding.Protocol, binding.BindingInformation(), binding.sslFlagsValue())
 		sb.WriteString(str)
+		if binding.CertificateThumbprint != "" {
+			// AddSslCertificate creates the HTTP.sys SSL binding (IP:port, or host name:port with SNI), replacing the previous one
+			sb.WriteString(fmt.Sprintf(`
+				$binding = Get-WebBinding -Name $siteName | Where-Object { $_.protocol -eq %q -and $_.bindingInformation -eq %q };
+				try { $binding.RemoveSslCertificate() } catch {};
+				$binding.AddSslCertificate(%q, %q);`, binding.Protocol, binding.BindingInformation(), binding.CertificateThumbprint, binding.CertificateStoreName))
+		}
 	}
 
 	command := sb.String()
@@ -156,9 +413,51 @@ func (client Client) updateWebSite(webSite WebSite) error {
 		return err
 	}
 
+	if webSite.LogFile != nil {
+		return client.updateCustomLogFields(webSite.Name, webSite.LogFile.CustomFields)
+	}
+
 	return nil
 }
 
+// siteIdParameter lets New-Website pick the next free id unless one is requested
+func siteIdParameter(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("-Id %s", id)
+}
+
+func (client Client) GetWebSiteState(name string) (string, error) {
+	bytes, err := client.Execute(fmt.Sprintf("(Get-WebsiteState -Name %q).Value", name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(*bytes)), nil
+}
+
+func (client Client) StartWebSite(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Start-Website -Name %q", name))
+	// HTTP.sys reports a binding already registered by another site or process as a sharing violation or as an existing file
+	if err != nil && (strings.Contains(err.Error(), "0x80070020") || strings.Contains(err.Error(), "0x800700B7")) {
+		return fmt.Errorf("web site '%s' could not be started because one of its bindings is already used by another site or process: %w", name, err)
+	}
+
+	return err
+}
+
+func (client Client) StopWebSite(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Stop-Website -Name %q", name))
+	return err
+}
+
+func (client Client) RestartWebSite(name string) error {
+	_, err := client.Execute(fmt.Sprintf("Stop-Website -Name %q; Start-Website -Name %q", name, name))
+	return err
+}
+
 func (client Client) DeleteWebSite(webSiteName string) error {
 	_, err := client.Execute(fmt.Sprintf("Remove-Website -Name %q", webSiteName))
 	if err != nil {
@@ -170,43 +469,183 @@ func (client Client) DeleteWebSite(webSiteName string) error {
 
 func mapWebSite(response *websiteResponse) *WebSite {
 	bindings := []Binding{}
-	for _, binding := range response.Bindings.Collection {
-		bindings = append(bindings, Binding{
-			Protocol:   binding.Protocol,
-			Ip:         binding.BindingInformation.Ip,
-			Port:       binding.BindingInformation.Port,
-			HostHeader: binding.BindingInformation.HostHeader,
-		})
+	for _, binding := range response.Bindings {
+		result := Binding{
+			Protocol:              binding.Protocol,
+			CertificateThumbprint: strings.ToUpper(binding.CertificateHash),
+			CertificateStoreName:  toCertificateStoreName(binding.CertificateStoreName),
+			SslFlags:              toSslFlagNames(int(binding.SslFlags)),
+		}
+
+		ip, port, hostHeader, err := ParseBindingInformation(binding.BindingInformation)
+		if IsIpProtocol(binding.Protocol) && err == nil {
+			result.Ip, result.Port, result.HostHeader = ip, port, hostHeader
+		} else {
+			// Kept as is, so that a binding information the provider does not understand shows up as a difference
+			result.Information = binding.BindingInformation
+		}
+
+		bindings = append(bindings, result)
 	}
+
+	enabledProtocols := []string{}
+	for _, protocol := range strings.Split(response.EnabledProtocols, ",") {
+		if protocol = strings.TrimSpace(protocol); protocol != "" {
+			enabledProtocols = append(enabledProtocols, protocol)
+		}
+	}
+
 	return &WebSite{
 		Id:                         strconv.Itoa(response.Id),
 		Name:                       response.Name,
 		State:                      response.State,
+		AutoStart:                  response.ServerAutoStart,
 		PhysicalPath:               response.PhysicalPath,
 		Username:                   response.Username,
-		Password:                   response.Password,
+		LogonMethod:                response.LogonMethod,
 		Bindings:                   bindings,
-		TraceFailedRequestsLogging: response.TraceFailedRequestsLogging,
+		EnabledProtocols:           enabledProtocols,
+		Limits:                     &response.Limits,
+		LogFile:                    &response.LogFile,
+		Hsts:                       &response.Hsts,
+		TraceFailedRequestsLogging: &response.TraceFailedRequestsLogging,
 		ApplicationPoolName:        response.ApplicationPool,
 	}
 }
 
-func (binding *bindingInformationResponse) UnmarshalJSON(data []byte) error {
-	var str string
-	if err := json.Unmarshal(data, &str); err != nil {
-		return err
+// IsIpProtocol reports whether the binding information of the protocol is made of an IP address, a port and a host name
+func IsIpProtocol(protocol string) bool {
+	return slices.Contains(ipProtocols, strings.ToLower(protocol))
+}
+
+// ParseBindingInformation splits an IP:port:host binding information, IPv6 addresses being enclosed in brackets as in [::1]:443:host
+func ParseBindingInformation(value string) (ip string, port int, hostHeader string, err error) {
+	rest := value
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]")
+		if end < 0 || !strings.HasPrefix(rest[end+1:], ":") {
+			return "", 0, "", fmt.Errorf("binding information '%s' has an invalid IPv6 address", value)
+		}
+
+		ip, rest = rest[1:end], rest[end+2:]
+	} else {
+		var found bool
+		ip, rest, found = strings.Cut(rest, ":")
+		if !found {
+			return "", 0, "", fmt.Errorf("binding information '%s' is not formatted as IP:port:host", value)
+		}
 	}
 
-	splitted := strings.Split(str, ":")
-	port, err := strconv.Atoi(splitted[1])
+	portValue, hostHeader, _ := strings.Cut(rest, ":")
+	port, err = strconv.Atoi(portValue)
 	if err != nil {
+		return "", 0, "", fmt.Errorf("binding information '%s' has an invalid port '%s'", value, portValue)
+	}
+
+	return ip, port, hostHeader, nil
+}
+
+// FormatBindingInformation is the reverse of ParseBindingInformation
+func FormatBindingInformation(ip string, port int, hostHeader string) string {
+	if strings.Contains(ip, ":") {
+		ip = fmt.Sprintf("[%s]", ip)
+	}
+
+	return fmt.Sprintf("%s:%d:%s", ip, port, hostHeader)
+}
+
+// BindingInformation returns the bindingInformation attribute of the binding
+func (binding Binding) BindingInformation() string {
+	if !IsIpProtocol(binding.Protocol) {
+		return binding.Information
+	}
+
+	return FormatBindingInformation(binding.Ip, binding.Port, binding.HostHeader)
+}
+
+func (binding Binding) sslFlagsValue() int {
+	value := 0
+	for _, flag := range sslFlags {
+		if slices.Contains(binding.SslFlags, flag.name) {
+			value |= flag.value
+		}
+	}
+
+	return value
+}
+
+func toSslFlagNames(value int) []string {
+	names := []string{}
+	for _, flag := range sslFlags {
+		if value&flag.value != 0 {
+			names = append(names, flag.name)
+		}
+	}
+
+	return names
+}
+
+// toCertificateStoreName returns the store name as configured, HTTP.sys reporting it in upper case and empty for the default one
+func toCertificateStoreName(name string) string {
+	if name == "" || strings.EqualFold(name, "My") {
+		return "My"
+	}
+
+	if strings.EqualFold(name, "WebHosting") {
+		return "WebHosting"
+	}
+
+	return name
+}
+
+// UnmarshalJSON reads the sslFlags either as a number or as the comma separated names of the flags
+func (flags *sslFlagsResponse) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*flags = sslFlagsResponse(number)
+		return nil
+	}
+
+	var names string
+	if err := json.Unmarshal(data, &names); err != nil {
 		return err
 	}
 
-	binding.Ip = splitted[0]
-	binding.Port = port
-	if len(splitted) > 2 {
-		binding.HostHeader = splitted[2]
+	value := 0
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		for _, flag := range sslFlags {
+			// The attribute names DisableOCSPStapling as DisableOCSPStp
+			if strings.EqualFold(name, flag.name) || (flag.name == "DisableOCSPStapling" && strings.EqualFold(name, "DisableOCSPStp")) {
+				value |= flag.value
+			}
+		}
+	}
+
+	*flags = sslFlagsResponse(value)
+	return nil
+}
+
+func (flags FlagList) String() string {
+	return strings.Join(flags, ",")
+}
+
+// UnmarshalJSON reads the comma separated names of the flags, null when the attribute does not exist on the host
+func (flags *FlagList) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*flags = FlagList{}
+	if value == nil {
+		return nil
+	}
+
+	for _, name := range strings.Split(*value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*flags = append(*flags, name)
+		}
 	}
 
 	return nil
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type WorkerProcess struct {
+	ApplicationPoolName string                 `json:"AppPoolName"`
+	ProcessId           int                    `json:"ProcessId"`
+	State               WorkerProcessState     `json:"State"`
+	StartTime           string                 `json:"StartTime"`
+	PrivateBytes        int64                  `json:"PrivateBytes"`
+	Requests            []WorkerProcessRequest `json:"Requests"`
+}
+
+type WorkerProcessRequest struct {
+	Url             string `json:"Url"`
+	Verb            string `json:"Verb"`
+	HostName        string `json:"HostName"`
+	ClientIpAddress string `json:"ClientIPAddr"`
+	CurrentModule   string `json:"CurrentModule"`
+	TimeElapsed     int    `json:"TimeElapsed"`
+}
+
+type WorkerProcessState string
+
+// GetWorkerProcesses lists the running worker processes of an application pool, or of every application pool when appPoolName is empty,
+// along with their requests that have been executing for longer than requestTimeElapsedFilter milliseconds
+func (client Client) GetWorkerProcesses(appPoolName string, requestTimeElapsedFilter int) ([]WorkerProcess, error) {
+	nameFilter := ""
+	if len(appPoolName) > 0 {
+		nameFilter = fmt.Sprintf("-Name %s -WarningAction Stop", toPowerShellString(appPoolName))
+	}
+
+	command := fmt.Sprintf(`
+		$workerProcesses = @(Get-IISAppPool %s | ForEach-Object { $_.WorkerProcesses } | ForEach-Object {
+			$process = Get-Process -Id $_.ProcessId -ErrorAction SilentlyContinue;
+			[PSCustomObject]@{
+				AppPoolName = $_.AppPoolName;
+				ProcessId = $_.ProcessId;
+				State = $_.State;
+				StartTime = $(if ($process) { $process.StartTime.ToUniversalTime().ToString('o') } else { '' });
+				PrivateBytes = $(if ($process) { $process.PrivateMemorySize64 } else { 0 });
+				Requests = @($_.GetRequests(%d) | Select-Object Url, Verb, HostName, ClientIPAddr, CurrentModule, TimeElapsed);
+			}
+		});
+		ConvertTo-Json -InputObject $workerProcesses -Depth 4 -Compress`, nameFilter, requestTimeElapsedFilter)
+	bytes, err := client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var workerProcesses []WorkerProcess
+	if err = json.Unmarshal(*bytes, &workerProcesses); err != nil {
+		return nil, err
+	}
+
+	return workerProcesses, nil
+}
+
+func (state *WorkerProcessState) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	switch number {
+	case 0:
+		*state = "Starting"
+	case 1:
+		*state = "Running"
+	case 2:
+		*state = "Stopping"
+	default:
+		*state = "Unknown"
+	}
+	return nil
+}
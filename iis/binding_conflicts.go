@@ -0,0 +1,115 @@
+package iis
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+// validateBindingConflictsDiff fails the plan when a binding of the site is already claimed by another site of
+// the host, as the second site would otherwise fail to start. The other sites are taken as they are on the host,
+// the plans of the other resources being unknown here, so a binding moved between two sites takes two applies
+func validateBindingConflictsDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange(webSiteSchema.BindingSchema.Key) || !d.NewValueKnown(webSiteSchema.BindingSchema.Key) || !d.NewValueKnown(webSiteSchema.Name) {
+		return nil
+	}
+
+	name := d.Get(webSiteSchema.Name).(string)
+	bindings := expandBindings(d.Get(webSiteSchema.BindingSchema.Key).(*schema.Set))
+
+	client, span := startSpan(ctx, m, "iis_web_site", "plan", name)
+	defer span.End()
+
+	webSites, err := client.ListWebSites()
+	if err != nil {
+		return err
+	}
+
+	others := map[string][]agent.Binding{}
+	for _, webSite := range webSites {
+		// The site itself is skipped by id as well, its name possibly being changed by the plan
+		if webSite.Name == name || (d.Id() != "" && webSite.Id == d.Id()) {
+			continue
+		}
+
+		others[webSite.Name] = webSite.Bindings
+	}
+
+	for i, binding := range bindings {
+		for _, other := range bindings[i+1:] {
+			if bindingsOverlap(binding, other) {
+				return fmt.Errorf("binding %s conflicts with the binding %s of the same web site", bindingName(binding), bindingName(other))
+			}
+		}
+
+		if err = checkSniBindings(binding, bindings[i+1:], name); err != nil {
+			return err
+		}
+	}
+
+	siteNames := make([]string, 0, len(others))
+	for siteName := range others {
+		siteNames = append(siteNames, siteName)
+	}
+	sort.Strings(siteNames)
+
+	for _, siteName := range siteNames {
+		for _, binding := range bindings {
+			for _, other := range others[siteName] {
+				if bindingsOverlap(binding, other) {
+					return fmt.Errorf("binding %s conflicts with the binding %s of web site '%s', a binding moved between two sites has to be removed from the first one in a prior apply", bindingName(binding), bindingName(other), siteName)
+				}
+			}
+
+			if err = checkSniBindings(binding, others[siteName], siteName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// bindingsOverlap tells whether both bindings would receive the same requests
+func bindingsOverlap(binding agent.Binding, other agent.Binding) bool {
+	// The WAS listeners of net.tcp, net.pipe and net.msmq are shared by every site of the host by design
+	if !agent.IsIpProtocol(binding.Protocol) || !agent.IsIpProtocol(other.Protocol) {
+		return false
+	}
+
+	// http and https listen on the same HTTP.sys endpoints, only ftp having its own
+	if (binding.Protocol == "ftp") != (other.Protocol == "ftp") {
+		return false
+	}
+
+	return strings.EqualFold(binding.Ip, other.Ip) && binding.Port == other.Port && strings.EqualFold(binding.HostHeader, other.HostHeader)
+}
+
+// checkSniBindings fails when the binding shares its https endpoint with other host names while one of them
+// does not use SNI, a single certificate being then bound to the IP and port for all of them
+func checkSniBindings(binding agent.Binding, others []agent.Binding, siteName string) error {
+	if binding.Protocol != "https" {
+		return nil
+	}
+
+	for _, other := range others {
+		if other.Protocol != "https" || !strings.EqualFold(binding.Ip, other.Ip) || binding.Port != other.Port || strings.EqualFold(binding.HostHeader, other.HostHeader) {
+			continue
+		}
+
+		if (binding.HostHeader != "" && !slices.Contains(binding.SslFlags, "Sni")) || (other.HostHeader != "" && !slices.Contains(other.SslFlags, "Sni")) {
+			return fmt.Errorf("binding %s shares its port with the binding %s of web site '%s', add 'Sni' to the %q of the host name bindings", bindingName(binding), bindingName(other), siteName, webSiteSchema.BindingSchema.SslFlags)
+		}
+	}
+
+	return nil
+}
+
+func bindingName(binding agent.Binding) string {
+	return fmt.Sprintf("%s://%s", binding.Protocol, binding.BindingInformation())
+}
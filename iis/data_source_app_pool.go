@@ -5,7 +5,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
 
 func dataSourceApplicationPool() *schema.Resource {
@@ -21,6 +20,11 @@ func dataSourceApplicationPool() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			applicationPoolSchema.AutoStart: {
+				Description: "If true, the application pool is started automatically when it is created or when IIS is started",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
 			applicationPoolSchema.StartMode: {
 				Description: "Configures application pool to run in On Demand Mode or Always Running Mode",
 				Type:        schema.TypeString,
@@ -46,6 +50,15 @@ func dataSourceApplicationPool() *schema.Resource {
 				Type:        schema.TypeInt,
 				Computed:    true,
 			},
+			applicationPoolSchema.EnvironmentVariables: {
+				Description: "Environment variables set for the worker process(es) of the application pool",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Sensitive:   true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			applicationPoolSchema.ProcessModelSchema.Key: {
 				Description: "Defines the process model settings for the application pool",
 				Type:        schema.TypeList,
@@ -63,9 +76,10 @@ func dataSourceApplicationPool() *schema.Resource {
 							Computed:    true,
 						},
 						applicationPoolSchema.ProcessModelSchema.Password: {
-							Description: "Configures the password for the specified identity",
+							Description: "The password is never read from the host, so this is always empty",
 							Type:        schema.TypeString,
 							Computed:    true,
+							Sensitive:   true,
 						},
 						applicationPoolSchema.ProcessModelSchema.LoadUserProfile: {
 							Description: "Specifies whether IIS loads the user profile for an application pool identity",
@@ -74,7 +88,7 @@ func dataSourceApplicationPool() *schema.Resource {
 						},
 						applicationPoolSchema.ProcessModelSchema.IdleTimeout: {
 							Description: "Amount of trime (in minutes) a worker process will remain idle befor it shuts down. A worker process is idle if it is not processing requests and no new requests are received",
-							Type:        schema.TypeInt,
+							Type:        schema.TypeString,
 							Computed:    true,
 						},
 						applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction: {
@@ -94,24 +108,248 @@ func dataSourceApplicationPool() *schema.Resource {
 						},
 						applicationPoolSchema.ProcessModelSchema.PingingInterval: {
 							Description: "Period of time (in seconds) between health monitoring pings sent to the worker process(es) serving the application",
-							Type:        schema.TypeInt,
+							Type:        schema.TypeString,
 							Computed:    true,
 						},
 						applicationPoolSchema.ProcessModelSchema.PingingResponseTime: {
 							Description: "Maximum time (in seconds) that a worker process is given to respond to a health monitoring ping. If the worker process does not respond, it is terminated",
-							Type:        schema.TypeInt,
+							Type:        schema.TypeString,
 							Computed:    true,
 						},
 						applicationPoolSchema.ProcessModelSchema.StartupTimeLimit: {
 							Description: "Period of time (in seconds) a worker process is given to start up and initialize. If the worker process initialization exceeds the startup time limit, it is terminated",
-							Type:        schema.TypeInt,
+							Type:        schema.TypeString,
 							Computed:    true,
 						},
 						applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit: {
 							Description: "Period of time (in seconds) a worker process is given to finish processing requests and shut down. If the worker process initialization exceeds the shutdown time limit, it is terminated",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.ProcessModelSchema.LogIdleTimeoutEvent: {
+							Description: "If true, an event is logged when a worker process is shut down or suspended because it reached the idle timeout",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.ProcessModelSchema.LogonType: {
+							Description: "The logon type used for the SpecificUser identity",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.ProcessModelSchema.ManualGroupMembership: {
+							Description: "If true, IIS does not add the IIS_IUSRS group to the token of the worker process",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.ProcessModelSchema.SetProfileEnvironment: {
+							Description: "If true, the environment variables of the user profile are set for the worker process",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.ProcessModelSchema.RequestQueueDelegatorIdentity: {
+							Description: "The identity allowed to delegate requests to the queue of the application pool",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			applicationPoolSchema.CPUSchema.Key: {
+				Description: "Defines the CPU usage limits and processor affinity of the application pool",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						applicationPoolSchema.CPUSchema.Limit: {
+							Description: "Maximum percentage of CPU time (in 1/1000ths of one percent) that the worker processes in an application pool are allowed to consume over a period of time as indicated by the limit interval. If set to 0, the CPU limit is disabled",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.LimitInterval: {
+							Description: "Reset period (in minutes) for CPU monitoring and throttling limits on the application pool",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.Action: {
+							Description: "Action to perform when the CPU limit is exceeded",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.ProcessorAffinityEnabled: {
+							Description: "If true, forces the worker process(es) serving this application pool to run on specific CPUs",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.ProcessorAffinityMask32: {
+							Description: "Processor mask for the lower 32 processors of a multi-processor computer",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.ProcessorAffinityMask64: {
+							Description: "Processor mask for the upper 32 processors of a 64-bit multi-processor computer",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.NumaNodeAssignment: {
+							Description: "How IIS determines which NUMA node to assign the worker process to",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.CPUSchema.NumaNodeAffinityMode: {
+							Description: "Whether the worker process threads may be scheduled outside of their NUMA node (Soft) or must stay on it (Hard)",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			applicationPoolSchema.RecyclingSchema.Key: {
+				Description: "Defines the conditions under which the worker process(es) of the application pool are recycled",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						applicationPoolSchema.RecyclingSchema.RegularTimeInterval: {
+							Description: "Period of time (in minutes) after which the application pool will recycle",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.Schedule: {
+							Description: "Specific local times (in 24 hour HH:MM format) at which the application pool will recycle",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						applicationPoolSchema.RecyclingSchema.RequestLimit: {
+							Description: "Maximum number of requests the application pool can process before it is recycled",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.VirtualMemoryLimit: {
+							Description: "Maximum amount of virtual memory (in KB) a worker process can consume before causing the application pool to recycle",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.PrivateMemoryLimit: {
+							Description: "Maximum amount of private memory (in KB) a worker process can consume before causing the application pool to recycle",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.DisableOverlappedRecycle: {
+							Description: "If true, when the application pool recycles, the existing worker process exits before another worker process is created",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.DisableRecycleOnConfigChange: {
+							Description: "If true, the application pool does not recycle when its configuration is changed",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.RecyclingSchema.LogEventsSchema.Key: {
+							Description: "Configures which application pool recycle events are written to the event log",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.Time: {
+										Description: "Whether recycles caused by the regular time interval are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.Requests: {
+										Description: "Whether recycles caused by the request limit are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.Schedule: {
+										Description: "Whether recycles at a scheduled time are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.Memory: {
+										Description: "Whether recycles caused by the virtual memory limit are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.IsapiUnhealthy: {
+										Description: "Whether recycles caused by an unhealthy ISAPI extension are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.OnDemand: {
+										Description: "Whether recycles requested on demand are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.ConfigChange: {
+										Description: "Whether recycles caused by the configuration change are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+									applicationPoolSchema.RecyclingSchema.LogEventsSchema.PrivateMemory: {
+										Description: "Whether recycles caused by the private memory limit are logged",
+										Type:        schema.TypeBool,
+										Computed:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			applicationPoolSchema.FailureSchema.Key: {
+				Description: "Defines the rapid-fail protection and worker process orphaning settings of the application pool",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						applicationPoolSchema.FailureSchema.RapidFailProtectionEnabled: {
+							Description: "If true, the application pool is shut down if there are a specified number of worker process crashes within a specified time period",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.RapidFailProtectionInterval: {
+							Description: "The time interval (in minutes) during which the specified number of worker process crashes must occur before the application pool is shut down by rapid-fail protection",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.RapidFailProtectionMaxCrashes: {
+							Description: "Maximum number of worker process crashes permitted before the application pool is shut down by rapid-fail protection",
 							Type:        schema.TypeInt,
 							Computed:    true,
 						},
+						applicationPoolSchema.FailureSchema.ShutdownExecutable: {
+							Description: "Executable to run when the application pool is shut down by rapid-fail protection",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.ShutdownExecutableParameters: {
+							Description: "Parameters for the executable that is run when the application pool is shut down by rapid-fail protection",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.ServiceUnavailableResponse: {
+							Description: "Whether HTTP.sys returns an HTTP 503 error (HttpLevel) or resets the connection (TcpLevel) when the application pool is stopped",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.OrphanWorkerProcess: {
+							Description: "If true, an unresponsive worker process will be abandoned (orphaned) instead of terminated",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.OrphanActionExecutable: {
+							Description: "Executable to run when a worker process is abandoned (orphaned)",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						applicationPoolSchema.FailureSchema.OrphanActionParameters: {
+							Description: "Parameters for the executable that is run when a worker process is abandoned (orphaned)",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
 					},
 				},
 			},
@@ -120,7 +358,9 @@ func dataSourceApplicationPool() *schema.Resource {
 }
 
 func dataSourceApplicationPoolRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "data.iis_application_pool", "read", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
+
 	name := d.Get(applicationPoolSchema.Name).(string)
 	appPool, err := client.GetAppPool(name)
 	if err != nil {
@@ -0,0 +1,96 @@
+package iis
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceApplicationPools() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the application pools of the host, optionally filtered",
+		ReadContext: dataSourceApplicationPoolsRead,
+		Schema: map[string]*schema.Schema{
+			applicationPoolsSchema.NameRegex: {
+				Description:      "Only lists the application pools whose name matches this regular expression",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateRegex(),
+			},
+			applicationPoolsSchema.State: {
+				Description:      "Only lists the application pools in this state",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateAllowedValues([]string{"Started", "Starting", "Stopped", "Stopping"}),
+			},
+			applicationPoolsSchema.PipelineMode: {
+				Description:      "Only lists the application pools running in this pipeline mode",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateAllowedValues([]string{"Integrated", "Classic"}),
+			},
+			applicationPoolsSchema.ManagedRuntimeVersion: {
+				Description: "Only lists the application pools loading this .NET CLR version (an empty string matches No Managed Code)",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			applicationPoolsSchema.ApplicationPools: {
+				Description: "The application pools matching every filter",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(dataSourceApplicationPool().Schema),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceApplicationPoolsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "data.iis_application_pools", "read", d.Get(applicationPoolsSchema.NameRegex).(string))
+	defer span.End()
+
+	nameRegex, err := compileNameRegex(d, applicationPoolsSchema.NameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	appPools, err := client.ListAppPools()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	state, hasState := d.GetOk(applicationPoolsSchema.State)
+	pipelineMode, hasPipelineMode := d.GetOk(applicationPoolsSchema.PipelineMode)
+	// The raw config is used because an empty runtime version is a meaningful filter
+	runtimeVersion := d.GetRawConfig().GetAttr(applicationPoolsSchema.ManagedRuntimeVersion)
+	result := []interface{}{}
+	for _, appPool := range appPools {
+		if nameRegex != nil && !nameRegex.MatchString(appPool.Name) {
+			continue
+		}
+
+		if hasState && appPool.State != state.(string) {
+			continue
+		}
+
+		if hasPipelineMode && appPool.PipelineMode != pipelineMode.(string) {
+			continue
+		}
+
+		if !runtimeVersion.IsNull() && appPool.ManagedRuntimeVersion != runtimeVersion.AsString() {
+			continue
+		}
+
+		result = append(result, flattenApplicationPool(appPool, ""))
+	}
+
+	if err = d.Set(applicationPoolsSchema.ApplicationPools, result); err != nil {
+		d.SetId("")
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
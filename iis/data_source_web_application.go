@@ -5,7 +5,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
 
 func dataSourceWebApplication() *schema.Resource {
@@ -28,9 +27,9 @@ func dataSourceWebApplication() *schema.Resource {
 				Required:    true,
 			},
 			webAppSchema.Site: {
-				Description: "Configures this web application to run in the specified application pool",
+				Description: "The web site the web application belongs to",
 				Type:        schema.TypeString,
-				Computed:    true,
+				Required:    true,
 			},
 			webAppSchema.ApplicationPoolName: {
 				Description: "Configures this web application to run in the specified application pool",
@@ -42,12 +41,29 @@ func dataSourceWebApplication() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			webAppSchema.Username: {
+				Description: "Username for the user identity that should be impersonated when accessing the physical path for the virtual directory",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			webAppSchema.Password: {
+				Description: "The password is never read from the host, so this is always empty",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			webAppSchema.LogonMethod: {
+				Description: "The logon type used to impersonate the physical path credentials of the virtual directory",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
 		},
 	}
 }
 
 func dataSourceWebApplicationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "data.iis_web_application", "read", d.Get(webAppSchema.Name).(string))
+	defer span.End()
 
 	site := d.Get(webAppSchema.Site).(string)
 	name := d.Get(webAppSchema.Name).(string)
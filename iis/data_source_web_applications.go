@@ -0,0 +1,86 @@
+package iis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWebApplications() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the web applications of the host, optionally filtered",
+		ReadContext: dataSourceWebApplicationsRead,
+		Schema: map[string]*schema.Schema{
+			webApplicationsSchema.NameRegex: {
+				Description:      "Only lists the web applications whose name matches this regular expression",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateRegex(),
+			},
+			webApplicationsSchema.Site: {
+				Description: "Only lists the web applications of this web site",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			webApplicationsSchema.ApplicationPoolName: {
+				Description: "Only lists the web applications running in this application pool",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			webApplicationsSchema.WebApplications: {
+				Description: "The web applications matching every filter",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(dataSourceWebApplication().Schema),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWebApplicationsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "data.iis_web_applications", "read", d.Get(webApplicationsSchema.NameRegex).(string))
+	defer span.End()
+
+	nameRegex, err := compileNameRegex(d, webApplicationsSchema.NameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	webApplications, err := client.ListWebApplications()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	site, hasSite := d.GetOk(webApplicationsSchema.Site)
+	appPoolName, hasAppPoolName := d.GetOk(webApplicationsSchema.ApplicationPoolName)
+	result := []interface{}{}
+	for _, webApplication := range webApplications {
+		if nameRegex != nil && !nameRegex.MatchString(webApplication.Name) {
+			continue
+		}
+
+		if hasSite && !strings.EqualFold(webApplication.Site, site.(string)) {
+			continue
+		}
+
+		if hasAppPoolName && !strings.EqualFold(webApplication.ApplicationPoolName, appPoolName.(string)) {
+			continue
+		}
+
+		application := flattenWebApplication(webApplication)
+		application[webAppSchema.Id] = webApplication.Id
+		result = append(result, application)
+	}
+
+	if err = d.Set(webApplicationsSchema.WebApplications, result); err != nil {
+		d.SetId("")
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
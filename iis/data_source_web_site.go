@@ -5,7 +5,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
 
 func dataSourceWebSite() *schema.Resource {
@@ -22,11 +21,21 @@ func dataSourceWebSite() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			webSiteSchema.SiteId: {
+				Description: "The numeric identifier of the site",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
 			webSiteSchema.State: {
 				Description: "The current state of the site",
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			webSiteSchema.AutoStart: {
+				Description: "If true, the site is started automatically when IIS is started",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
 			webSiteSchema.ApplicationPoolName: {
 				Description: "Configures this web site to run in the specified application pool",
 				Type:        schema.TypeString,
@@ -43,17 +52,117 @@ func dataSourceWebSite() *schema.Resource {
 				Computed:    true,
 			},
 			webSiteSchema.Password: {
-				Description: "Password for the user identity that should be impersonated when accessing the physical path for the virtual directory",
+				Description: "The password is never read from the host, so this is always empty",
 				Type:        schema.TypeString,
 				Computed:    true,
+				Sensitive:   true,
+			},
+			webSiteSchema.LogonMethod: {
+				Description: "The logon type used to impersonate the physical path credentials",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			webSiteSchema.EnabledProtocols: {
+				Description: "The protocols the requests can use to access the site",
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			webSiteSchema.LogFileSchema.Key: {
+				Description: "Where and how the requests of the site are logged",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(webSiteLogFileSchema),
+				},
+			},
+			webSiteSchema.HstsSchema.Key: {
+				Description: "The HTTP Strict Transport Security of the site",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(webSiteHstsSchema),
+				},
+			},
+			webSiteSchema.TracingSchema.Key: {
+				Description: "Where the traces of the failed requests of the site are written",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(webSiteTracingSchema),
+				},
+			},
+			webSiteSchema.LimitsSchema.Key: {
+				Description: "The bandwidth, connection and URL limits of the site",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(webSiteLimitsSchema),
+				},
+			},
+			webSiteSchema.BindingSchema.Key: {
+				Description: "The bindings of the site, each one a combination of protocol, IP address, port and host name",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						webSiteSchema.BindingSchema.Protocol: {
+							Description: "The protocol of the binding",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.Ip: {
+							Description: "The IP address the binding listens on",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.Port: {
+							Description: "The port the binding listens on",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.HostHeader: {
+							Description: "The host name of the binding",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.BindingInformation: {
+							Description: "The binding information of the WAS protocols, such as 808:* for net.tcp",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.CertificateThumbprint: {
+							Description: "The thumbprint of the certificate bound to an https binding",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.CertificateStoreName: {
+							Description: "The certificate store containing the certificate",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						webSiteSchema.BindingSchema.SslFlags: {
+							Description: "The SSL flags of an https binding",
+							Type:        schema.TypeSet,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
 func dataSourceWebSiteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
-	name := d.Get(webAppSchema.Name).(string)
+	client, span := startSpan(ctx, m, "data.iis_web_site", "read", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
+
+	name := d.Get(webSiteSchema.Name).(string)
 	webSite, err := client.GetWebSite(name)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,126 @@
+package iis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func dataSourceWebSites() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the web sites of the host, optionally filtered",
+		ReadContext: dataSourceWebSitesRead,
+		Schema: map[string]*schema.Schema{
+			webSitesSchema.NameRegex: {
+				Description:      "Only lists the web sites whose name matches this regular expression",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateRegex(),
+			},
+			webSitesSchema.State: {
+				Description:      "Only lists the web sites in this state",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateAllowedValues([]string{"Started", "Starting", "Stopped", "Stopping"}),
+			},
+			webSitesSchema.ApplicationPoolName: {
+				Description: "Only lists the web sites running in this application pool",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			webSitesSchema.BindingHostHeader: {
+				Description: "Only lists the web sites with at least one binding for this host name (case insensitive)",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			webSitesSchema.BindingPort: {
+				Description:      "Only lists the web sites with at least one binding on this port",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				ValidateDiagFunc: isInBetweenValues(1, 65535),
+			},
+			webSitesSchema.WebSites: {
+				Description: "The web sites matching every filter",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: computedSchema(dataSourceWebSite().Schema),
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWebSitesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "data.iis_web_sites", "read", d.Get(webSitesSchema.NameRegex).(string))
+	defer span.End()
+
+	nameRegex, err := compileNameRegex(d, webSitesSchema.NameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	webSites, err := client.ListWebSites()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	state, hasState := d.GetOk(webSitesSchema.State)
+	appPoolName, hasAppPoolName := d.GetOk(webSitesSchema.ApplicationPoolName)
+	hostHeader := d.Get(webSitesSchema.BindingHostHeader).(string)
+	port := d.Get(webSitesSchema.BindingPort).(int)
+	result := []interface{}{}
+	for _, webSite := range webSites {
+		if nameRegex != nil && !nameRegex.MatchString(webSite.Name) {
+			continue
+		}
+
+		if hasState && webSite.State != state.(string) {
+			continue
+		}
+
+		if hasAppPoolName && !strings.EqualFold(webSite.ApplicationPoolName, appPoolName.(string)) {
+			continue
+		}
+
+		if !hasMatchingBinding(webSite.Bindings, hostHeader, port) {
+			continue
+		}
+
+		site := flattenWebSite(webSite)
+		site[webSiteSchema.Id] = webSite.Id
+		result = append(result, site)
+	}
+
+	if err = d.Set(webSitesSchema.WebSites, result); err != nil {
+		d.SetId("")
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
+
+// hasMatchingBinding reports whether one binding matches both the host header and the port, ignoring the empty filters
+func hasMatchingBinding(bindings []agent.Binding, hostHeader string, port int) bool {
+	if hostHeader == "" && port == 0 {
+		return true
+	}
+
+	for _, binding := range bindings {
+		if hostHeader != "" && !strings.EqualFold(binding.HostHeader, hostHeader) {
+			continue
+		}
+
+		if port != 0 && binding.Port != port {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package iis
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func dataSourceWorkerProcesses() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the running worker processes of the application pools and the requests they are currently executing",
+		ReadContext: dataSourceWorkerProcessesRead,
+		Schema: map[string]*schema.Schema{
+			workerProcessesSchema.ApplicationPoolName: {
+				Description: "The application pool whose worker processes are listed. If not set, the worker processes of every application pool are listed",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			workerProcessesSchema.RequestTimeElapsedFilter: {
+				Description:      "Only lists the requests that have been executing for at least this amount of time (in milliseconds)",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: greaterOrEqualThan(0),
+			},
+			workerProcessesSchema.WorkerProcessSchema.Key: {
+				Description: "The running worker processes",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						workerProcessesSchema.WorkerProcessSchema.ApplicationPoolName: {
+							Description: "The application pool served by the worker process",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						workerProcessesSchema.WorkerProcessSchema.ProcessId: {
+							Description: "The process identifier (PID) of the worker process",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						workerProcessesSchema.WorkerProcessSchema.State: {
+							Description: "The state of the worker process (Starting, Running, Stopping or Unknown)",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						workerProcessesSchema.WorkerProcessSchema.StartTime: {
+							Description: "The time (in RFC 3339 format, UTC) at which the worker process started",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						workerProcessesSchema.WorkerProcessSchema.PrivateBytes: {
+							Description: "The amount of private memory (in bytes) allocated by the worker process",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						workerProcessesSchema.WorkerProcessSchema.RequestSchema.Key: {
+							Description: "The requests currently executing in the worker process",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.Url: {
+										Description: "The requested URL",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.Verb: {
+										Description: "The HTTP verb of the request",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.HostName: {
+										Description: "The host name the request was sent to",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.ClientIpAddress: {
+										Description: "The IP address of the client",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.CurrentModule: {
+										Description: "The module currently processing the request",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									workerProcessesSchema.WorkerProcessSchema.RequestSchema.TimeElapsed: {
+										Description: "The time (in milliseconds) the request has been executing for",
+										Type:        schema.TypeInt,
+										Computed:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWorkerProcessesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	appPoolName := d.Get(workerProcessesSchema.ApplicationPoolName).(string)
+	client, span := startSpan(ctx, m, "data.iis_worker_processes", "read", appPoolName)
+	defer span.End()
+
+	requestTimeElapsedFilter := d.Get(workerProcessesSchema.RequestTimeElapsedFilter).(int)
+	workerProcesses, err := client.GetWorkerProcesses(appPoolName, requestTimeElapsedFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = mapWorkerProcessesToResourceData(workerProcesses, d); err != nil {
+		d.SetId("")
+		return diag.FromErr(err)
+	}
+
+	if len(appPoolName) > 0 {
+		d.SetId(appPoolName)
+	} else {
+		d.SetId("*")
+	}
+
+	return nil
+}
+
+func mapWorkerProcessesToResourceData(workerProcesses []agent.WorkerProcess, d *schema.ResourceData) error {
+	processes := []interface{}{}
+	for _, workerProcess := range workerProcesses {
+		requests := []interface{}{}
+		for _, request := range workerProcess.Requests {
+			requests = append(requests, map[string]interface{}{
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.Url:             request.Url,
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.Verb:            request.Verb,
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.HostName:        request.HostName,
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.ClientIpAddress: request.ClientIpAddress,
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.CurrentModule:   request.CurrentModule,
+				workerProcessesSchema.WorkerProcessSchema.RequestSchema.TimeElapsed:     request.TimeElapsed,
+			})
+		}
+
+		processes = append(processes, map[string]interface{}{
+			workerProcessesSchema.WorkerProcessSchema.ApplicationPoolName: workerProcess.ApplicationPoolName,
+			workerProcessesSchema.WorkerProcessSchema.ProcessId:           workerProcess.ProcessId,
+			workerProcessesSchema.WorkerProcessSchema.State:               string(workerProcess.State),
+			workerProcessesSchema.WorkerProcessSchema.StartTime:           workerProcess.StartTime,
+			workerProcessesSchema.WorkerProcessSchema.PrivateBytes:        int(workerProcess.PrivateBytes),
+			workerProcessesSchema.WorkerProcessSchema.RequestSchema.Key:   requests,
+		})
+	}
+
+	return d.Set(workerProcessesSchema.WorkerProcessSchema.Key, processes)
+}
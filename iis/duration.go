@@ -0,0 +1,86 @@
+package iis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+// noMaximumDuration is used as the upper bound of the durations IIS does not limit
+const noMaximumDuration = time.Duration(math.MaxInt64)
+
+// parseDuration parses the value of a duration attribute, which is either a number expressed in the unit of the attribute,
+// a Go duration such as "36h" or "1h30m", or a .NET TimeSpan such as "1.12:00:00"
+func parseDuration(value string, unit time.Duration) (agent.TimeSpan, error) {
+	value = strings.TrimSpace(value)
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return agent.TimeSpan(time.Duration(number) * unit), nil
+	}
+
+	if strings.Contains(value, ":") {
+		return agent.ParseTimeSpan(value)
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' must be a number, a duration such as 36h or a TimeSpan such as 1.12:00:00", value)
+	}
+
+	return agent.TimeSpan(duration), nil
+}
+
+// getDuration reads an already validated duration attribute
+func getDuration(value interface{}, unit time.Duration) agent.TimeSpan {
+	duration, _ := parseDuration(value.(string), unit)
+	return duration
+}
+
+// formatDuration writes a duration read from the host as a number in the unit of the attribute whenever it is a whole number,
+// so that the values written before durations were accepted do not change
+func formatDuration(timeSpan agent.TimeSpan, unit time.Duration) string {
+	duration := time.Duration(timeSpan)
+	if duration%unit == 0 {
+		return strconv.FormatInt(int64(duration/unit), 10)
+	}
+
+	return duration.String()
+}
+
+func isDurationInBetween(unit time.Duration, minValue time.Duration, maxValue time.Duration) schema.SchemaValidateDiagFunc {
+	return func(val interface{}, path cty.Path) diag.Diagnostics {
+		duration, err := parseDuration(val.(string), unit)
+		if err != nil {
+			return diag.Errorf("%q is not valid: %s", path, err)
+		}
+
+		if time.Duration(duration) < minValue || time.Duration(duration) > maxValue {
+			if maxValue == noMaximumDuration {
+				return diag.Errorf("%q must be at least %v", path, minValue)
+			}
+
+			return diag.Errorf("%q must be between %v and %v", path, minValue, maxValue)
+		}
+
+		return nil
+	}
+}
+
+// suppressEquivalentDuration hides the differences between two notations of the same duration, e.g. 1440 minutes and "24h"
+func suppressEquivalentDuration(unit time.Duration) schema.SchemaDiffSuppressFunc {
+	return func(k, oldValue, newValue string, d *schema.ResourceData) bool {
+		oldDuration, err := parseDuration(oldValue, unit)
+		if err != nil {
+			return false
+		}
+
+		newDuration, err := parseDuration(newValue, unit)
+		return err == nil && oldDuration == newDuration
+	}
+}
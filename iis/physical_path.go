@@ -0,0 +1,168 @@
+package iis
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+var createPhysicalPathAttribute = &schema.Schema{
+	Description: "If true, the directory of the physical path is created when it does not exist",
+	Type:        schema.TypeBool,
+	Optional:    true,
+	Default:     true,
+}
+
+var physicalPathPermissionAttribute = &schema.Schema{
+	Description: "The permissions granted on the directory of the physical path, inherited by its content. They are granted on creation and when they or the physical path change, but never read back from the host. Nothing is granted when empty",
+	Type:        schema.TypeSet,
+	Optional:    true,
+	Elem: &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			physicalPathSchema.PermissionSchema.Identity: {
+				Description: "The user or group the rights are granted to, e.g. IIS_IUSRS or 'IIS AppPool\\DefaultAppPool'",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			physicalPathSchema.PermissionSchema.Rights: {
+				Description:      "The rights granted: Read, ReadAndExecute, Write, Modify or FullControl",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "ReadAndExecute",
+				ValidateDiagFunc: validateAllowedValues(agent.PathRights),
+			},
+		},
+	},
+}
+
+var onDestroyAttribute = &schema.Schema{
+	Description:      "What happens to the directory of the physical path when the resource is destroyed: keep, remove_empty to remove it only when it is empty, or remove_content to remove it along with its content. The directory is kept while another site or application uses it, and a replacement keeping the same physical path cannot be planned with remove_content",
+	Type:             schema.TypeString,
+	Optional:         true,
+	Default:          "keep",
+	ValidateDiagFunc: validateAllowedValues([]string{"keep", "remove_empty", "remove_content"}),
+}
+
+// expandPhysicalPathProvisioning maps the provisioning of the physical path stored at physicalPathKey
+func expandPhysicalPathProvisioning(d *schema.ResourceData, physicalPathKey string) agent.PhysicalPathProvisioning {
+	provisioning := agent.PhysicalPathProvisioning{
+		Create: d.Get(physicalPathSchema.CreatePhysicalPath).(bool),
+	}
+
+	// Granting the permissions at each apply would be slow on big directories, and they are not read back anyway
+	isNew := d.Id() == ""
+	if !isNew && !d.HasChanges(physicalPathSchema.PermissionSchema.Key, physicalPathKey) {
+		return provisioning
+	}
+
+	oldPermissions, newPermissions := d.GetChange(physicalPathSchema.PermissionSchema.Key)
+	identities := []string{}
+	for _, value := range newPermissions.(*schema.Set).List() {
+		permission := value.(map[string]interface{})
+		identity := permission[physicalPathSchema.PermissionSchema.Identity].(string)
+		identities = append(identities, strings.ToLower(identity))
+		provisioning.Permissions = append(provisioning.Permissions, agent.PathPermission{
+			Identity: identity,
+			Rights:   permission[physicalPathSchema.PermissionSchema.Rights].(string),
+		})
+	}
+
+	// A new directory has nothing to revoke, the previous one being left as is
+	if isNew || d.HasChange(physicalPathKey) {
+		return provisioning
+	}
+
+	for _, value := range oldPermissions.(*schema.Set).List() {
+		identity := value.(map[string]interface{})[physicalPathSchema.PermissionSchema.Identity].(string)
+		if !slices.Contains(identities, strings.ToLower(identity)) && !slices.Contains(provisioning.Revoked, identity) {
+			provisioning.Revoked = append(provisioning.Revoked, identity)
+		}
+	}
+
+	return provisioning
+}
+
+// destroyPhysicalPath removes the directory of the physical path stored at physicalPathKey, as requested by on_destroy,
+// unless another site or application of the host still uses it or a directory inside it
+func destroyPhysicalPath(client *agent.Client, d *schema.ResourceData, physicalPathKey string) diag.Diagnostics {
+	onDestroy := d.Get(physicalPathSchema.OnDestroy).(string)
+	if onDestroy == "keep" {
+		return nil
+	}
+
+	physicalPath := d.Get(physicalPathKey).(string)
+	user, err := findPhysicalPathUser(client, physicalPath)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if user != "" {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("The directory '%s' was kept", physicalPath),
+			Detail:   fmt.Sprintf("The directory is still used by %s, so it was not removed as requested by %s.", user, physicalPathSchema.OnDestroy),
+		}}
+	}
+
+	return diag.FromErr(client.RemovePhysicalPath(physicalPath, onDestroy == "remove_content"))
+}
+
+// findPhysicalPathUser names the site or application whose physical path is the directory or a directory inside it,
+// or returns an empty string when there is none. The resource being destroyed is already gone from the host
+func findPhysicalPathUser(client *agent.Client, physicalPath string) (string, error) {
+	webSites, err := client.ListWebSites()
+	if err != nil {
+		return "", err
+	}
+
+	for _, webSite := range webSites {
+		if isPhysicalPathWithin(webSite.PhysicalPath, physicalPath) {
+			return fmt.Sprintf("web site '%s'", webSite.Name), nil
+		}
+	}
+
+	webApplications, err := client.ListWebApplications()
+	if err != nil {
+		return "", err
+	}
+
+	for _, webApplication := range webApplications {
+		if isPhysicalPathWithin(webApplication.PhysicalPath, physicalPath) {
+			return fmt.Sprintf("web application '%s/%s'", webApplication.Site, webApplication.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// isPhysicalPathWithin tells whether the path is the directory or lies inside it, the paths being compared as written
+// in the configuration of IIS, environment variables included
+func isPhysicalPathWithin(path string, directory string) bool {
+	normalize := func(value string) string {
+		return strings.TrimRight(strings.ToLower(strings.ReplaceAll(value, "/", `\`)), `\`)
+	}
+
+	path, directory = normalize(path), normalize(directory)
+	return path != "" && (path == directory || strings.HasPrefix(path, directory+`\`))
+}
+
+// validatePhysicalPathReplacementDiff fails the plan of a replacement keeping the same physical path while on_destroy
+// removes the content, as destroying the replaced resource would empty the directory of the new one
+func validatePhysicalPathReplacementDiff(d *schema.ResourceDiff, physicalPathKey string, forceNewKeys ...string) error {
+	if d.Id() == "" || !d.HasChanges(forceNewKeys...) || d.HasChange(physicalPathKey) {
+		return nil
+	}
+
+	// The replaced resource is destroyed as its state says
+	onDestroy, _ := d.GetChange(physicalPathSchema.OnDestroy)
+	if onDestroy.(string) != "remove_content" {
+		return nil
+	}
+
+	return fmt.Errorf("the replacement keeps the physical path '%s' whose content %s would remove when destroying the replaced resource, set %[2]s to keep or remove_empty and apply before replacing it",
+		d.Get(physicalPathKey).(string), physicalPathSchema.OnDestroy)
+}
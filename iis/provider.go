@@ -28,14 +28,22 @@ func Provider() *schema.Provider {
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"iis_application_pool": resourceApplicationPool(),
-			"iis_web_site":         resourceWebsite(),
-			"iis_web_application":  resourceWebApplication(),
+			"iis_application_pool":            resourceApplicationPool(),
+			"iis_application_pool_defaults":   resourceApplicationPoolDefaults(),
+			"iis_web_site":                    resourceWebsite(),
+			"iis_site_defaults":               resourceSiteDefaults(),
+			"iis_web_application":             resourceWebApplication(),
+			"iis_application_defaults":        resourceApplicationDefaults(),
+			"iis_failed_request_tracing_rule": resourceFailedRequestTracingRule(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"iis_application_pool": dataSourceApplicationPool(),
-			"iis_web_site":         dataSourceWebSite(),
-			"iis_web_application":  dataSourceWebApplication(),
+			"iis_application_pool":  dataSourceApplicationPool(),
+			"iis_application_pools": dataSourceApplicationPools(),
+			"iis_web_site":          dataSourceWebSite(),
+			"iis_web_sites":         dataSourceWebSites(),
+			"iis_web_application":   dataSourceWebApplication(),
+			"iis_web_applications":  dataSourceWebApplications(),
+			"iis_worker_processes":  dataSourceWorkerProcesses(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -2,8 +2,16 @@ package iis
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
@@ -16,9 +24,14 @@ func resourceApplicationPool() *schema.Resource {
 		ReadContext:   resourceApplicationPoolRead,
 		UpdateContext: resourceApplicationPoolUpdate,
 		DeleteContext: resourceApplicationPoolDelete,
+		CustomizeDiff: customizeApplicationPoolDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: importApplicationPoolState,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			applicationPoolSchema.Name: {
 				Description: "The application pool name is the unique identifier for the application pool",
@@ -27,9 +40,17 @@ func resourceApplicationPool() *schema.Resource {
 				ForceNew:    true,
 			},
 			applicationPoolSchema.State: {
-				Description: "The current state of the application pool",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:      "The desired state of the application pool. The application pool is started or stopped in place, and stopping it outside of Terraform is reported as drift",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "Started",
+				ValidateDiagFunc: validateAllowedValues([]string{"Started", "Stopped"}),
+			},
+			applicationPoolSchema.AutoStart: {
+				Description: "If true, the application pool is started automatically when it is created or when IIS is started",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
 			},
 			applicationPoolSchema.StartMode: {
 				Description:      "Configures application pool to run in On Demand Mode or Always Running Mode",
@@ -65,6 +86,55 @@ func resourceApplicationPool() *schema.Resource {
 				Default:          1000,
 				ValidateDiagFunc: isInBetweenValues(10, 65535),
 			},
+			applicationPoolSchema.EnvironmentVariables: {
+				Description: "Environment variables set for the worker process(es) of the application pool. Requires IIS 10 or later",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			applicationPoolSchema.SensitiveEnvironmentVariables: {
+				Description: "Environment variables set for the worker process(es) of the application pool whose values are hidden from the plan output. The names must not overlap with environment_variables",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			applicationPoolSchema.RecycleTriggers: {
+				Description: "Arbitrary map of values that, when changed, recycles the application pool in place. For example, the version of the deployed content",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			applicationPoolSchema.RecycleWaitForWorkerProcess: {
+				Description: "If true, a recycle caused by recycle_triggers waits until a new worker process is running. With the OnDemand start mode, the new worker process only starts on the first request",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			applicationPoolSchema.ForceDelete: {
+				Description: "If true, the application pool is deleted even when web sites or web applications still run in it, leaving them broken",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			applicationPoolSchema.ReassignTo: {
+				Description: "Application pool to which the web sites and web applications still running in this one are moved before it is deleted",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+			applicationPoolSchema.DeletionProtection: {
+				Description: "If true, the application pool cannot be deleted (nor replaced) until this is set back to false and applied",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 			applicationPoolSchema.ProcessModelSchema.Key: {
 				Description: "Defines the process model settings for the application pool",
 				Type:        schema.TypeList,
@@ -74,10 +144,99 @@ func resourceApplicationPool() *schema.Resource {
 					Schema: processModelSchema,
 				},
 			},
+			applicationPoolSchema.CPUSchema.Key: {
+				Description: "Defines the CPU usage limits and processor affinity of the application pool, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: cpuSchema,
+				},
+			},
+			applicationPoolSchema.RecyclingSchema.Key: {
+				Description: "Defines the conditions under which the worker process(es) of the application pool are recycled, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: recyclingSchema,
+				},
+			},
+			applicationPoolSchema.FailureSchema.Key: {
+				Description: "Defines the rapid-fail protection and worker process orphaning settings of the application pool, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: failureSchema,
+				},
+			},
 		},
 	}
 }
 
+var cpuSchema = map[string]*schema.Schema{
+	applicationPoolSchema.CPUSchema.Limit: {
+		Description:      "Maximum percentage of CPU time (in 1/1000ths of one percent) that the worker processes in an application pool are allowed to consume over a period of time as indicated by the limit interval. If set to 0, the CPU limit is disabled",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          0,
+		ValidateDiagFunc: isInBetweenValues(0, 100000),
+	},
+	applicationPoolSchema.CPUSchema.LimitInterval: {
+		Description:      "Reset period for CPU monitoring and throttling limits on the application pool, in minutes or as a duration such as \"1h\". When the time elapsed since the last process accounting reset equals this period, IIS will reset the CPU timers for both the logging and limit intervals",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "5",
+		ValidateDiagFunc: isDurationInBetween(time.Minute, 0, 24*time.Hour),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Minute),
+	},
+	applicationPoolSchema.CPUSchema.Action: {
+		Description:      "Action to perform when the CPU limit is exceeded. NoAction only logs an event, KillW3wp shuts down the worker process, Throttle limits the CPU consumption to the limit and ThrottleUnderLoad only does so when there is contention on the CPU",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "NoAction",
+		ValidateDiagFunc: validateAllowedValues([]string{"NoAction", "KillW3wp", "Throttle", "ThrottleUnderLoad"}),
+	},
+	applicationPoolSchema.CPUSchema.ProcessorAffinityEnabled: {
+		Description: "If true, forces the worker process(es) serving this application pool to run on specific CPUs, which are configured by the processor affinity masks",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.CPUSchema.ProcessorAffinityMask32: {
+		Description:      "Hexadecimal processor mask, as a decimal number, for the lower 32 processors of a multi-processor computer, which indicates the CPUs the worker process(es) should be bound to. Processor affinity must be enabled for this to be effective",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "4294967295",
+		ValidateDiagFunc: isUint32InBetween(0, math.MaxUint32),
+	},
+	applicationPoolSchema.CPUSchema.ProcessorAffinityMask64: {
+		Description:      "Hexadecimal processor mask, as a decimal number, for the upper 32 processors of a 64-bit multi-processor computer, which indicates the CPUs the worker process(es) should be bound to. Processor affinity must be enabled for this to be effective",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "4294967295",
+		ValidateDiagFunc: isUint32InBetween(0, math.MaxUint32),
+	},
+	applicationPoolSchema.CPUSchema.NumaNodeAssignment: {
+		Description:      "How IIS determines which NUMA node to assign the worker process to. MostAvailableMemory assigns it to the node with the most free memory and WindowsScheduling lets Windows decide",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "MostAvailableMemory",
+		ValidateDiagFunc: validateAllowedValues([]string{"MostAvailableMemory", "WindowsScheduling"}),
+	},
+	applicationPoolSchema.CPUSchema.NumaNodeAffinityMode: {
+		Description:      "Whether the worker process threads may be scheduled outside of their NUMA node (Soft) or must stay on it (Hard)",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "Soft",
+		ValidateDiagFunc: validateAllowedValues([]string{"Soft", "Hard"}),
+	},
+}
+
 var processModelSchema = map[string]*schema.Schema{
 	applicationPoolSchema.ProcessModelSchema.IdentityType: {
 		Description:      "Configures the application pool to run as a built-in account",
@@ -87,15 +246,16 @@ var processModelSchema = map[string]*schema.Schema{
 		ValidateDiagFunc: validateAllowedValues([]string{"LocalSystem", "LocalService", "NetworkService", "SpecificUser", "ApplicationPoolIdentity"}),
 	},
 	applicationPoolSchema.ProcessModelSchema.Username: {
-		Description: "Configures the username for the specified identity",
+		Description: "Configures the username for the SpecificUser identity. Group Managed Service Accounts are supported in the 'DOMAIN\\account$' format, without a password",
 		Type:        schema.TypeString,
 		Optional:    true,
 		Default:     "",
 	},
 	applicationPoolSchema.ProcessModelSchema.Password: {
-		Description: "Configures the password for the specified identity",
+		Description: "Configures the password for the SpecificUser identity. It is never read back from the host, so changes made outside of Terraform are not detected",
 		Type:        schema.TypeString,
 		Optional:    true,
+		Sensitive:   true,
 		Default:     "",
 	},
 	applicationPoolSchema.ProcessModelSchema.LoadUserProfile: {
@@ -105,11 +265,12 @@ var processModelSchema = map[string]*schema.Schema{
 		Default:     true,
 	},
 	applicationPoolSchema.ProcessModelSchema.IdleTimeout: {
-		Description:      "Amount of trime (in minutes) a worker process will remain idle befor it shuts down. A worker process is idle if it is not processing requests and no new requests are received",
-		Type:             schema.TypeInt,
+		Description:      "Amount of time a worker process will remain idle before it shuts down, in minutes or as a duration such as \"36h\". A worker process is idle if it is not processing requests and no new requests are received",
+		Type:             schema.TypeString,
 		Optional:         true,
-		Default:          20,
-		ValidateDiagFunc: isInBetweenValues(0, 43200),
+		Default:          "20",
+		ValidateDiagFunc: isDurationInBetween(time.Minute, 0, 30*24*time.Hour),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Minute),
 	},
 	applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction: {
 		Description:      "What action to perform when the Idle Time-out duration has been reached",
@@ -132,37 +293,247 @@ var processModelSchema = map[string]*schema.Schema{
 		Default:     true,
 	},
 	applicationPoolSchema.ProcessModelSchema.PingingInterval: {
-		Description:      "Period of time (in seconds) between health monitoring pings sent to the worker process(es) serving the application",
-		Type:             schema.TypeInt,
+		Description:      "Period of time between health monitoring pings sent to the worker process(es) serving the application, in seconds or as a duration such as \"1m\"",
+		Type:             schema.TypeString,
 		Optional:         true,
-		Default:          30,
-		ValidateDiagFunc: isInBetweenValues(1, 4294967),
+		Default:          "30",
+		ValidateDiagFunc: isDurationInBetween(time.Second, time.Second, 4294967*time.Second),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Second),
 	},
 	applicationPoolSchema.ProcessModelSchema.PingingResponseTime: {
-		Description:      "Maximum time (in seconds) that a worker process is given to respond to a health monitoring ping. If the worker process does not respond, it is terminated",
-		Type:             schema.TypeInt,
+		Description:      "Maximum time that a worker process is given to respond to a health monitoring ping, in seconds or as a duration such as \"2m\". If the worker process does not respond, it is terminated",
+		Type:             schema.TypeString,
 		Optional:         true,
-		Default:          90,
-		ValidateDiagFunc: isInBetweenValues(1, 4294967),
+		Default:          "90",
+		ValidateDiagFunc: isDurationInBetween(time.Second, time.Second, 4294967*time.Second),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Second),
 	},
 	applicationPoolSchema.ProcessModelSchema.StartupTimeLimit: {
-		Description:      "Period of time (in seconds) a worker process is given to start up and initialize. If the worker process initialization exceeds the startup time limit, it is terminated",
-		Type:             schema.TypeInt,
+		Description:      "Period of time a worker process is given to start up and initialize, in seconds or as a duration such as \"2m\". If the worker process initialization exceeds the startup time limit, it is terminated",
+		Type:             schema.TypeString,
 		Optional:         true,
-		Default:          90,
-		ValidateDiagFunc: isInBetweenValues(1, 4294967),
+		Default:          "90",
+		ValidateDiagFunc: isDurationInBetween(time.Second, time.Second, 4294967*time.Second),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Second),
 	},
 	applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit: {
-		Description:      "Period of time (in seconds) a worker process is given to finish processing requests and shut down. If the worker process initialization exceeds the shutdown time limit, it is terminated",
+		Description:      "Period of time a worker process is given to finish processing requests and shut down, in seconds or as a duration such as \"2m\". If the worker process exceeds the shutdown time limit, it is terminated",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "90",
+		ValidateDiagFunc: isDurationInBetween(time.Second, time.Second, 4294967*time.Second),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Second),
+	},
+	applicationPoolSchema.ProcessModelSchema.LogIdleTimeoutEvent: {
+		Description: "If true, an event is logged when a worker process is shut down or suspended because it reached the idle timeout",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+	applicationPoolSchema.ProcessModelSchema.LogonType: {
+		Description:      "The logon type used for the SpecificUser identity: LogonBatch or LogonService",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "LogonBatch",
+		ValidateDiagFunc: validateAllowedValues([]string{"LogonBatch", "LogonService"}),
+	},
+	applicationPoolSchema.ProcessModelSchema.ManualGroupMembership: {
+		Description: "If true, IIS does not add the IIS_IUSRS group to the token of the worker process, which must then be granted its permissions explicitly",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.ProcessModelSchema.SetProfileEnvironment: {
+		Description: "If true, the environment variables of the user profile (e.g. TEMP) are set for the worker process when load_user_profile is enabled. Requires IIS 10 or later",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.ProcessModelSchema.RequestQueueDelegatorIdentity: {
+		Description: "The identity allowed to delegate requests to the queue of the application pool, for example when requests are forwarded by the IIS out-of-process hosting model. Requires IIS 10 or later",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+}
+
+var recyclingSchema = map[string]*schema.Schema{
+	applicationPoolSchema.RecyclingSchema.RegularTimeInterval: {
+		Description:      "Period of time after which the application pool will recycle, in minutes or as a duration such as \"29h\". If set to 0, the application pool will not recycle on a regular interval",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "1740",
+		ValidateDiagFunc: isDurationInBetween(time.Minute, 0, noMaximumDuration),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Minute),
+	},
+	applicationPoolSchema.RecyclingSchema.Schedule: {
+		Description: "Specific local times (in 24 hour HH:MM format) at which the application pool will recycle",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: isValid(regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)),
+		},
+	},
+	applicationPoolSchema.RecyclingSchema.RequestLimit: {
+		Description:      "Maximum number of requests the application pool can process before it is recycled. If set to 0, the application pool can process an unlimited number of requests",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          0,
+		ValidateDiagFunc: greaterOrEqualThan(0),
+	},
+	applicationPoolSchema.RecyclingSchema.VirtualMemoryLimit: {
+		Description:      "Maximum amount of virtual memory (in KB) a worker process can consume before causing the application pool to recycle. If set to 0, there is no limit",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          0,
+		ValidateDiagFunc: greaterOrEqualThan(0),
+	},
+	applicationPoolSchema.RecyclingSchema.PrivateMemoryLimit: {
+		Description:      "Maximum amount of private memory (in KB) a worker process can consume before causing the application pool to recycle. If set to 0, there is no limit",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          0,
+		ValidateDiagFunc: greaterOrEqualThan(0),
+	},
+	applicationPoolSchema.RecyclingSchema.DisableOverlappedRecycle: {
+		Description: "If true, when the application pool recycles, the existing worker process exits before another worker process is created. Set to true if the worker process loads an application that does not support multiple instances",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.DisableRecycleOnConfigChange: {
+		Description: "If true, the application pool does not recycle when its configuration is changed",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.Key: {
+		Description: "Configures which application pool recycle events are written to the event log",
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: recyclingLogEventsSchema,
+		},
+	},
+}
+
+var recyclingLogEventsSchema = map[string]*schema.Schema{
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.Time: {
+		Description: "Logs an event when the application pool recycles on its regular time interval",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.Requests: {
+		Description: "Logs an event when the application pool recycles after reaching its request limit",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.Schedule: {
+		Description: "Logs an event when the application pool recycles at a scheduled time",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.Memory: {
+		Description: "Logs an event when the application pool recycles after reaching its virtual memory limit",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.IsapiUnhealthy: {
+		Description: "Logs an event when the application pool recycles because an ISAPI extension reported itself as unhealthy",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.OnDemand: {
+		Description: "Logs an event when the application pool is recycled on demand",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.ConfigChange: {
+		Description: "Logs an event when the application pool recycles because its configuration changed",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.RecyclingSchema.LogEventsSchema.PrivateMemory: {
+		Description: "Logs an event when the application pool recycles after reaching its private memory limit",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+}
+
+var failureSchema = map[string]*schema.Schema{
+	applicationPoolSchema.FailureSchema.RapidFailProtectionEnabled: {
+		Description: "If true, the application pool is shut down if there are a specified number of worker process crashes within a specified time period",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+	applicationPoolSchema.FailureSchema.RapidFailProtectionInterval: {
+		Description:      "The time interval during which the specified number of worker process crashes must occur before the application pool is shut down by rapid-fail protection, in minutes or as a duration such as \"1h\"",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "5",
+		ValidateDiagFunc: isDurationInBetween(time.Minute, time.Minute, 100*24*time.Hour),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Minute),
+	},
+	applicationPoolSchema.FailureSchema.RapidFailProtectionMaxCrashes: {
+		Description:      "Maximum number of worker process crashes permitted before the application pool is shut down by rapid-fail protection",
 		Type:             schema.TypeInt,
 		Optional:         true,
-		Default:          90,
-		ValidateDiagFunc: isInBetweenValues(1, 4294967),
+		Default:          5,
+		ValidateDiagFunc: greaterOrEqualThan(0),
+	},
+	applicationPoolSchema.FailureSchema.ShutdownExecutable: {
+		Description: "Executable to run when the application pool is shut down by rapid-fail protection. This can be used to reconfigure a load balancer",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+	applicationPoolSchema.FailureSchema.ShutdownExecutableParameters: {
+		Description: "Parameters for the executable that is run when the application pool is shut down by rapid-fail protection",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+	applicationPoolSchema.FailureSchema.ServiceUnavailableResponse: {
+		Description:      "If set to HttpLevel and the application pool is stopped, HTTP.sys will return an HTTP 503 error. If set to TcpLevel, HTTP.sys will reset the connection. This is useful if the load balancer recognizes one of the response types and subsequently redirects it",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "HttpLevel",
+		ValidateDiagFunc: validateAllowedValues([]string{"HttpLevel", "TcpLevel"}),
+	},
+	applicationPoolSchema.FailureSchema.OrphanWorkerProcess: {
+		Description: "If true, an unresponsive worker process will be abandoned (orphaned) instead of terminated. This can be used to debug a worker process failure",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	applicationPoolSchema.FailureSchema.OrphanActionExecutable: {
+		Description: "Executable to run when a worker process is abandoned (orphaned). For example, an executable that creates a dump of the worker process",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+	applicationPoolSchema.FailureSchema.OrphanActionParameters: {
+		Description: "Parameters for the executable that is run when a worker process is abandoned (orphaned). For example, %1% is replaced with the process ID",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
 	},
 }
 
 func resourceApplicationPoolCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_application_pool", "create", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
 
 	appPoolRequest := mapToApplicationPool(d)
 	appPool, err := client.CreateAppPool(appPoolRequest)
@@ -172,11 +543,17 @@ func resourceApplicationPoolCreate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId(appPool.Id)
+	if err = applyAppPoolState(ctx, client, appPool.Name, d.Get(applicationPoolSchema.State).(string), d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
 func resourceApplicationPoolRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_application_pool", "read", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
+
 	name := d.Get(applicationPoolSchema.Name).(string)
 	appPool, err := client.GetAppPool(name)
 	if err != nil {
@@ -188,11 +565,16 @@ func resourceApplicationPoolRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 
+	if err = mapAppPoolEnvironmentVariablesToResourceData(*appPool, d); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
 func resourceApplicationPoolUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_application_pool", "update", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
 
 	appPool := mapToApplicationPool(d)
 	err := client.UpdateAppPool(appPool)
@@ -202,12 +584,35 @@ func resourceApplicationPoolUpdate(ctx context.Context, d *schema.ResourceData,
 	}
 
 	d.SetId(appPool.Name)
+	if d.HasChange(applicationPoolSchema.State) {
+		if err = applyAppPoolState(ctx, client, appPool.Name, appPool.State, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange(applicationPoolSchema.RecycleTriggers) && appPool.State == "Started" {
+		waitForWorkerProcess := d.Get(applicationPoolSchema.RecycleWaitForWorkerProcess).(bool)
+		if err = recycleAppPool(ctx, client, appPool.Name, waitForWorkerProcess, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return nil
 }
 
 func resourceApplicationPoolDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_application_pool", "delete", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
+
 	name := d.Get(applicationPoolSchema.Name).(string)
+	if d.Get(applicationPoolSchema.DeletionProtection).(bool) {
+		return diag.Errorf("application pool '%s' has deletion_protection enabled, set it to false and apply before deleting it", name)
+	}
+
+	if err := releaseAppPool(client, name, d.Get(applicationPoolSchema.ReassignTo).(string), d.Get(applicationPoolSchema.ForceDelete).(bool)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	err := client.DeleteAppPool(name)
 	if err != nil {
 		return diag.FromErr(err)
@@ -217,7 +622,9 @@ func resourceApplicationPoolDelete(ctx context.Context, d *schema.ResourceData,
 }
 
 func importApplicationPoolState(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	client := meta.(*agent.Client)
+	client, span := startSpan(ctx, meta, "iis_application_pool", "import", d.Id())
+	defer span.End()
+
 	appPoolName := d.Id()
 	appPool, err := client.GetAppPool(appPoolName)
 	if err != nil {
@@ -229,94 +636,494 @@ func importApplicationPoolState(ctx context.Context, d *schema.ResourceData, met
 		return nil, err
 	}
 
+	if err = mapAppPoolEnvironmentVariablesToResourceData(*appPool, d); err != nil {
+		return nil, err
+	}
+
 	return []*schema.ResourceData{d}, nil
 }
 
 func mapToApplicationPool(d *schema.ResourceData) agent.ApplicationPool {
+	environmentVariables := map[string]string{}
+	for _, key := range []string{applicationPoolSchema.EnvironmentVariables, applicationPoolSchema.SensitiveEnvironmentVariables} {
+		for name, value := range d.Get(key).(map[string]interface{}) {
+			environmentVariables[name] = value.(string)
+		}
+	}
+
+	appPool := mapToApplicationPoolSettings(d)
+	appPool.Name = d.Get(applicationPoolSchema.Name).(string)
+	appPool.State = d.Get(applicationPoolSchema.State).(string)
+	appPool.EnvironmentVariables = environmentVariables
+	return appPool
+}
+
+// mapToApplicationPoolSettings maps the attributes shared by iis_application_pool and iis_application_pool_defaults
+func mapToApplicationPoolSettings(d *schema.ResourceData) agent.ApplicationPool {
 	var processModel agent.ProcessModel
+	var password string
 	processModelResourceList := d.Get(applicationPoolSchema.ProcessModelSchema.Key).([]interface{})
 	if len(processModelResourceList) > 0 {
 		processModelResource := processModelResourceList[0].(map[string]interface{})
+		password = processModelResource[applicationPoolSchema.ProcessModelSchema.Password].(string)
 		processModel = agent.ProcessModel{
-			IdentityType:      processModelResource[applicationPoolSchema.ProcessModelSchema.IdentityType].(string),
-			Username:          processModelResource[applicationPoolSchema.ProcessModelSchema.Username].(string),
-			LoadUserProfile:   processModelResource[applicationPoolSchema.ProcessModelSchema.LoadUserProfile].(bool),
-			IdleTimeout:       processModelResource[applicationPoolSchema.ProcessModelSchema.IdleTimeout].(int),
-			IdleTimeoutAction: processModelResource[applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction].(string),
-			MaxProcesses:      processModelResource[applicationPoolSchema.ProcessModelSchema.MaxProcesses].(int),
-			PingingEnabled:    processModelResource[applicationPoolSchema.ProcessModelSchema.PingingEnabled].(bool),
-			PingInterval:      processModelResource[applicationPoolSchema.ProcessModelSchema.PingingInterval].(int),
-			PingResponseTime:  processModelResource[applicationPoolSchema.ProcessModelSchema.PingingResponseTime].(int),
-			StartupTimeLimit:  processModelResource[applicationPoolSchema.ProcessModelSchema.StartupTimeLimit].(int),
-			ShutdownTimeLimit: processModelResource[applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit].(int),
+			IdentityType:                  processModelResource[applicationPoolSchema.ProcessModelSchema.IdentityType].(string),
+			Username:                      processModelResource[applicationPoolSchema.ProcessModelSchema.Username].(string),
+			Password:                      &password,
+			LoadUserProfile:               processModelResource[applicationPoolSchema.ProcessModelSchema.LoadUserProfile].(bool),
+			IdleTimeout:                   getDuration(processModelResource[applicationPoolSchema.ProcessModelSchema.IdleTimeout], time.Minute),
+			IdleTimeoutAction:             processModelResource[applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction].(string),
+			MaxProcesses:                  processModelResource[applicationPoolSchema.ProcessModelSchema.MaxProcesses].(int),
+			PingingEnabled:                processModelResource[applicationPoolSchema.ProcessModelSchema.PingingEnabled].(bool),
+			PingInterval:                  getDuration(processModelResource[applicationPoolSchema.ProcessModelSchema.PingingInterval], time.Second),
+			PingResponseTime:              getDuration(processModelResource[applicationPoolSchema.ProcessModelSchema.PingingResponseTime], time.Second),
+			StartupTimeLimit:              getDuration(processModelResource[applicationPoolSchema.ProcessModelSchema.StartupTimeLimit], time.Second),
+			ShutdownTimeLimit:             getDuration(processModelResource[applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit], time.Second),
+			LogIdleTimeoutEvent:           processModelResource[applicationPoolSchema.ProcessModelSchema.LogIdleTimeoutEvent].(bool),
+			LogonType:                     processModelResource[applicationPoolSchema.ProcessModelSchema.LogonType].(string),
+			ManualGroupMembership:         processModelResource[applicationPoolSchema.ProcessModelSchema.ManualGroupMembership].(bool),
+			SetProfileEnvironment:         processModelResource[applicationPoolSchema.ProcessModelSchema.SetProfileEnvironment].(bool),
+			RequestQueueDelegatorIdentity: processModelResource[applicationPoolSchema.ProcessModelSchema.RequestQueueDelegatorIdentity].(string),
 		}
 	} else {
+		password = processModelSchema[applicationPoolSchema.ProcessModelSchema.Password].Default.(string)
 		processModel = agent.ProcessModel{
-			IdentityType:      processModelSchema[applicationPoolSchema.ProcessModelSchema.IdentityType].Default.(string),
-			Username:          processModelSchema[applicationPoolSchema.ProcessModelSchema.Username].Default.(string),
-			LoadUserProfile:   processModelSchema[applicationPoolSchema.ProcessModelSchema.LoadUserProfile].Default.(bool),
-			IdleTimeout:       processModelSchema[applicationPoolSchema.ProcessModelSchema.IdleTimeout].Default.(int),
-			IdleTimeoutAction: processModelSchema[applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction].Default.(string),
-			MaxProcesses:      processModelSchema[applicationPoolSchema.ProcessModelSchema.MaxProcesses].Default.(int),
-			PingingEnabled:    processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingEnabled].Default.(bool),
-			PingInterval:      processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingInterval].Default.(int),
-			PingResponseTime:  processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingResponseTime].Default.(int),
-			StartupTimeLimit:  processModelSchema[applicationPoolSchema.ProcessModelSchema.StartupTimeLimit].Default.(int),
-			ShutdownTimeLimit: processModelSchema[applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit].Default.(int),
+			IdentityType:                  processModelSchema[applicationPoolSchema.ProcessModelSchema.IdentityType].Default.(string),
+			Username:                      processModelSchema[applicationPoolSchema.ProcessModelSchema.Username].Default.(string),
+			Password:                      &password,
+			LoadUserProfile:               processModelSchema[applicationPoolSchema.ProcessModelSchema.LoadUserProfile].Default.(bool),
+			IdleTimeout:                   getDuration(processModelSchema[applicationPoolSchema.ProcessModelSchema.IdleTimeout].Default, time.Minute),
+			IdleTimeoutAction:             processModelSchema[applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction].Default.(string),
+			MaxProcesses:                  processModelSchema[applicationPoolSchema.ProcessModelSchema.MaxProcesses].Default.(int),
+			PingingEnabled:                processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingEnabled].Default.(bool),
+			PingInterval:                  getDuration(processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingInterval].Default, time.Second),
+			PingResponseTime:              getDuration(processModelSchema[applicationPoolSchema.ProcessModelSchema.PingingResponseTime].Default, time.Second),
+			StartupTimeLimit:              getDuration(processModelSchema[applicationPoolSchema.ProcessModelSchema.StartupTimeLimit].Default, time.Second),
+			ShutdownTimeLimit:             getDuration(processModelSchema[applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit].Default, time.Second),
+			LogIdleTimeoutEvent:           processModelSchema[applicationPoolSchema.ProcessModelSchema.LogIdleTimeoutEvent].Default.(bool),
+			LogonType:                     processModelSchema[applicationPoolSchema.ProcessModelSchema.LogonType].Default.(string),
+			ManualGroupMembership:         processModelSchema[applicationPoolSchema.ProcessModelSchema.ManualGroupMembership].Default.(bool),
+			SetProfileEnvironment:         processModelSchema[applicationPoolSchema.ProcessModelSchema.SetProfileEnvironment].Default.(bool),
+			RequestQueueDelegatorIdentity: processModelSchema[applicationPoolSchema.ProcessModelSchema.RequestQueueDelegatorIdentity].Default.(string),
 		}
 	}
 
+	cpuResource := getBlockOrDefaults(d, applicationPoolSchema.CPUSchema.Key, cpuSchema)
+	cpu := agent.CPU{
+		Limit:                    cpuResource[applicationPoolSchema.CPUSchema.Limit].(int),
+		LimitInterval:            getDuration(cpuResource[applicationPoolSchema.CPUSchema.LimitInterval], time.Minute),
+		Action:                   cpuResource[applicationPoolSchema.CPUSchema.Action].(string),
+		ProcessorAffinityEnabled: cpuResource[applicationPoolSchema.CPUSchema.ProcessorAffinityEnabled].(bool),
+		ProcessorAffinityMask32:  getInt64(cpuResource[applicationPoolSchema.CPUSchema.ProcessorAffinityMask32]),
+		ProcessorAffinityMask64:  getInt64(cpuResource[applicationPoolSchema.CPUSchema.ProcessorAffinityMask64]),
+		NumaNodeAssignment:       cpuResource[applicationPoolSchema.CPUSchema.NumaNodeAssignment].(string),
+		NumaNodeAffinityMode:     cpuResource[applicationPoolSchema.CPUSchema.NumaNodeAffinityMode].(string),
+	}
+
+	recyclingResource := getBlockOrDefaults(d, applicationPoolSchema.RecyclingSchema.Key, recyclingSchema)
+	logEventsResource := blockOrDefaults(recyclingResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.Key], recyclingLogEventsSchema)
+	var schedule []string
+	for _, restartTime := range recyclingResource[applicationPoolSchema.RecyclingSchema.Schedule].([]interface{}) {
+		schedule = append(schedule, restartTime.(string))
+	}
+
+	recycling := agent.Recycling{
+		DisableOverlappedRecycle:     recyclingResource[applicationPoolSchema.RecyclingSchema.DisableOverlappedRecycle].(bool),
+		DisableRecycleOnConfigChange: recyclingResource[applicationPoolSchema.RecyclingSchema.DisableRecycleOnConfigChange].(bool),
+		LogEvents: agent.LogEvents{
+			Time:           logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.Time].(bool),
+			Requests:       logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.Requests].(bool),
+			Schedule:       logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.Schedule].(bool),
+			Memory:         logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.Memory].(bool),
+			IsapiUnhealthy: logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.IsapiUnhealthy].(bool),
+			OnDemand:       logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.OnDemand].(bool),
+			ConfigChange:   logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.ConfigChange].(bool),
+			PrivateMemory:  logEventsResource[applicationPoolSchema.RecyclingSchema.LogEventsSchema.PrivateMemory].(bool),
+		},
+		PeriodicRestart: agent.PeriodicRestart{
+			TimeInterval:  getDuration(recyclingResource[applicationPoolSchema.RecyclingSchema.RegularTimeInterval], time.Minute),
+			Schedule:      schedule,
+			RequestLimit:  recyclingResource[applicationPoolSchema.RecyclingSchema.RequestLimit].(int),
+			VirtualMemory: recyclingResource[applicationPoolSchema.RecyclingSchema.VirtualMemoryLimit].(int),
+			PrivateMemory: recyclingResource[applicationPoolSchema.RecyclingSchema.PrivateMemoryLimit].(int),
+		},
+	}
+
+	failureResource := getBlockOrDefaults(d, applicationPoolSchema.FailureSchema.Key, failureSchema)
+	failure := agent.Failure{
+		RapidFailProtectionEnabled:    failureResource[applicationPoolSchema.FailureSchema.RapidFailProtectionEnabled].(bool),
+		RapidFailProtectionInterval:   getDuration(failureResource[applicationPoolSchema.FailureSchema.RapidFailProtectionInterval], time.Minute),
+		RapidFailProtectionMaxCrashes: failureResource[applicationPoolSchema.FailureSchema.RapidFailProtectionMaxCrashes].(int),
+		AutoShutdownExe:               failureResource[applicationPoolSchema.FailureSchema.ShutdownExecutable].(string),
+		AutoShutdownParams:            failureResource[applicationPoolSchema.FailureSchema.ShutdownExecutableParameters].(string),
+		LoadBalancerCapabilities:      failureResource[applicationPoolSchema.FailureSchema.ServiceUnavailableResponse].(string),
+		OrphanWorkerProcessEnabled:    failureResource[applicationPoolSchema.FailureSchema.OrphanWorkerProcess].(bool),
+		OrphanActionExe:               failureResource[applicationPoolSchema.FailureSchema.OrphanActionExecutable].(string),
+		OrphanActionParams:            failureResource[applicationPoolSchema.FailureSchema.OrphanActionParameters].(string),
+	}
+
 	appPool := agent.ApplicationPool{
-		Name:                  d.Get(applicationPoolSchema.Name).(string),
+		AutoStart:             d.Get(applicationPoolSchema.AutoStart).(bool),
 		StartMode:             d.Get(applicationPoolSchema.StartMode).(string),
 		PipelineMode:          d.Get(applicationPoolSchema.PipelineMode).(string),
 		ManagedRuntimeVersion: d.Get(applicationPoolSchema.RuntimeVersion).(string),
 		Enable32BitWin64:      d.Get(applicationPoolSchema.Enable32Bit).(bool),
 		QueueLength:           d.Get(applicationPoolSchema.QueueLength).(int),
 		ProcessModel:          processModel,
+		CPU:                   cpu,
+		Recycling:             recycling,
+		Failure:               failure,
 	}
 
 	return appPool
 }
 
 func mapAppPoolToResourceData(appPool agent.ApplicationPool, d *schema.ResourceData) error {
-	var err error
 	d.SetId(appPool.Id)
-	if err = d.Set(applicationPoolSchema.Name, appPool.Name); err != nil {
+
+	for key, value := range flattenApplicationPool(appPool, configuredAppPoolPassword(d)) {
+		if err := d.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configuredAppPoolPassword returns the password of the state, as it is write-only and never read from the host
+func configuredAppPoolPassword(d *schema.ResourceData) string {
+	if processModelList, ok := d.Get(applicationPoolSchema.ProcessModelSchema.Key).([]interface{}); ok && len(processModelList) > 0 && processModelList[0] != nil {
+		return processModelList[0].(map[string]interface{})[applicationPoolSchema.ProcessModelSchema.Password].(string)
+	}
+
+	return ""
+}
+
+// flattenApplicationPool maps the application pool into the attributes shared by the resource and the data sources
+func flattenApplicationPool(appPool agent.ApplicationPool, password string) map[string]interface{} {
+	processModel := map[string]interface{}{
+		applicationPoolSchema.ProcessModelSchema.IdentityType:                  appPool.ProcessModel.IdentityType,
+		applicationPoolSchema.ProcessModelSchema.Username:                      appPool.ProcessModel.Username,
+		applicationPoolSchema.ProcessModelSchema.Password:                      password,
+		applicationPoolSchema.ProcessModelSchema.LoadUserProfile:               appPool.ProcessModel.LoadUserProfile,
+		applicationPoolSchema.ProcessModelSchema.IdleTimeout:                   formatDuration(appPool.ProcessModel.IdleTimeout, time.Minute),
+		applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction:             appPool.ProcessModel.IdleTimeoutAction,
+		applicationPoolSchema.ProcessModelSchema.MaxProcesses:                  appPool.ProcessModel.MaxProcesses,
+		applicationPoolSchema.ProcessModelSchema.PingingEnabled:                appPool.ProcessModel.PingingEnabled,
+		applicationPoolSchema.ProcessModelSchema.PingingInterval:               formatDuration(appPool.ProcessModel.PingInterval, time.Second),
+		applicationPoolSchema.ProcessModelSchema.PingingResponseTime:           formatDuration(appPool.ProcessModel.PingResponseTime, time.Second),
+		applicationPoolSchema.ProcessModelSchema.StartupTimeLimit:              formatDuration(appPool.ProcessModel.StartupTimeLimit, time.Second),
+		applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit:             formatDuration(appPool.ProcessModel.ShutdownTimeLimit, time.Second),
+		applicationPoolSchema.ProcessModelSchema.LogIdleTimeoutEvent:           appPool.ProcessModel.LogIdleTimeoutEvent,
+		applicationPoolSchema.ProcessModelSchema.LogonType:                     appPool.ProcessModel.LogonType,
+		applicationPoolSchema.ProcessModelSchema.ManualGroupMembership:         appPool.ProcessModel.ManualGroupMembership,
+		applicationPoolSchema.ProcessModelSchema.SetProfileEnvironment:         appPool.ProcessModel.SetProfileEnvironment,
+		applicationPoolSchema.ProcessModelSchema.RequestQueueDelegatorIdentity: appPool.ProcessModel.RequestQueueDelegatorIdentity,
+	}
+
+	cpu := map[string]interface{}{
+		applicationPoolSchema.CPUSchema.Limit:                    appPool.CPU.Limit,
+		applicationPoolSchema.CPUSchema.LimitInterval:            formatDuration(appPool.CPU.LimitInterval, time.Minute),
+		applicationPoolSchema.CPUSchema.Action:                   appPool.CPU.Action,
+		applicationPoolSchema.CPUSchema.ProcessorAffinityEnabled: appPool.CPU.ProcessorAffinityEnabled,
+		applicationPoolSchema.CPUSchema.ProcessorAffinityMask32:  strconv.FormatInt(appPool.CPU.ProcessorAffinityMask32, 10),
+		applicationPoolSchema.CPUSchema.ProcessorAffinityMask64:  strconv.FormatInt(appPool.CPU.ProcessorAffinityMask64, 10),
+		applicationPoolSchema.CPUSchema.NumaNodeAssignment:       appPool.CPU.NumaNodeAssignment,
+		applicationPoolSchema.CPUSchema.NumaNodeAffinityMode:     appPool.CPU.NumaNodeAffinityMode,
+	}
+
+	logEvents := map[string]interface{}{
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.Time:           appPool.Recycling.LogEvents.Time,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.Requests:       appPool.Recycling.LogEvents.Requests,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.Schedule:       appPool.Recycling.LogEvents.Schedule,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.Memory:         appPool.Recycling.LogEvents.Memory,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.IsapiUnhealthy: appPool.Recycling.LogEvents.IsapiUnhealthy,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.OnDemand:       appPool.Recycling.LogEvents.OnDemand,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.ConfigChange:   appPool.Recycling.LogEvents.ConfigChange,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.PrivateMemory:  appPool.Recycling.LogEvents.PrivateMemory,
+	}
+
+	recycling := map[string]interface{}{
+		applicationPoolSchema.RecyclingSchema.RegularTimeInterval:          formatDuration(appPool.Recycling.PeriodicRestart.TimeInterval, time.Minute),
+		applicationPoolSchema.RecyclingSchema.Schedule:                     appPool.Recycling.PeriodicRestart.Schedule,
+		applicationPoolSchema.RecyclingSchema.RequestLimit:                 appPool.Recycling.PeriodicRestart.RequestLimit,
+		applicationPoolSchema.RecyclingSchema.VirtualMemoryLimit:           appPool.Recycling.PeriodicRestart.VirtualMemory,
+		applicationPoolSchema.RecyclingSchema.PrivateMemoryLimit:           appPool.Recycling.PeriodicRestart.PrivateMemory,
+		applicationPoolSchema.RecyclingSchema.DisableOverlappedRecycle:     appPool.Recycling.DisableOverlappedRecycle,
+		applicationPoolSchema.RecyclingSchema.DisableRecycleOnConfigChange: appPool.Recycling.DisableRecycleOnConfigChange,
+		applicationPoolSchema.RecyclingSchema.LogEventsSchema.Key:          []interface{}{logEvents},
+	}
+
+	failure := map[string]interface{}{
+		applicationPoolSchema.FailureSchema.RapidFailProtectionEnabled:    appPool.Failure.RapidFailProtectionEnabled,
+		applicationPoolSchema.FailureSchema.RapidFailProtectionInterval:   formatDuration(appPool.Failure.RapidFailProtectionInterval, time.Minute),
+		applicationPoolSchema.FailureSchema.RapidFailProtectionMaxCrashes: appPool.Failure.RapidFailProtectionMaxCrashes,
+		applicationPoolSchema.FailureSchema.ShutdownExecutable:            appPool.Failure.AutoShutdownExe,
+		applicationPoolSchema.FailureSchema.ShutdownExecutableParameters:  appPool.Failure.AutoShutdownParams,
+		applicationPoolSchema.FailureSchema.ServiceUnavailableResponse:    appPool.Failure.LoadBalancerCapabilities,
+		applicationPoolSchema.FailureSchema.OrphanWorkerProcess:           appPool.Failure.OrphanWorkerProcessEnabled,
+		applicationPoolSchema.FailureSchema.OrphanActionExecutable:        appPool.Failure.OrphanActionExe,
+		applicationPoolSchema.FailureSchema.OrphanActionParameters:        appPool.Failure.OrphanActionParams,
+	}
+
+	environmentVariables := map[string]interface{}{}
+	for name, value := range appPool.EnvironmentVariables {
+		environmentVariables[name] = value
+	}
+
+	return map[string]interface{}{
+		applicationPoolSchema.Name:                   appPool.Name,
+		applicationPoolSchema.State:                  appPool.State,
+		applicationPoolSchema.AutoStart:              appPool.AutoStart,
+		applicationPoolSchema.StartMode:              appPool.StartMode,
+		applicationPoolSchema.PipelineMode:           appPool.PipelineMode,
+		applicationPoolSchema.RuntimeVersion:         appPool.ManagedRuntimeVersion,
+		applicationPoolSchema.Enable32Bit:            appPool.Enable32BitWin64,
+		applicationPoolSchema.QueueLength:            appPool.QueueLength,
+		applicationPoolSchema.EnvironmentVariables:   environmentVariables,
+		applicationPoolSchema.ProcessModelSchema.Key: []interface{}{processModel},
+		applicationPoolSchema.CPUSchema.Key:          []interface{}{cpu},
+		applicationPoolSchema.RecyclingSchema.Key:    []interface{}{recycling},
+		applicationPoolSchema.FailureSchema.Key:      []interface{}{failure},
+	}
+}
+
+// mapAppPoolEnvironmentVariablesToResourceData splits the variables read from the host between the plain and the sensitive maps,
+// keeping the variables known as sensitive in the sensitive one
+func mapAppPoolEnvironmentVariablesToResourceData(appPool agent.ApplicationPool, d *schema.ResourceData) error {
+	sensitiveNames := d.Get(applicationPoolSchema.SensitiveEnvironmentVariables).(map[string]interface{})
+	environmentVariables := map[string]interface{}{}
+	sensitiveEnvironmentVariables := map[string]interface{}{}
+	for name, value := range appPool.EnvironmentVariables {
+		if _, ok := sensitiveNames[name]; ok {
+			sensitiveEnvironmentVariables[name] = value
+		} else {
+			environmentVariables[name] = value
+		}
+	}
+
+	if err := d.Set(applicationPoolSchema.EnvironmentVariables, environmentVariables); err != nil {
+		return err
+	}
+
+	return d.Set(applicationPoolSchema.SensitiveEnvironmentVariables, sensitiveEnvironmentVariables)
+}
+
+func customizeApplicationPoolDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := validateEnvironmentVariablesDiff(d); err != nil {
 		return err
 	}
-	if err = d.Set(applicationPoolSchema.StartMode, appPool.StartMode); err != nil {
+
+	if err := validateIdleTimeoutActionDiff(ctx, d, m); err != nil {
 		return err
 	}
-	if err = d.Set(applicationPoolSchema.PipelineMode, appPool.PipelineMode); err != nil {
+
+	processModelKey := applicationPoolSchema.ProcessModelSchema.Key
+	usernameKey := fmt.Sprintf("%s.0.%s", processModelKey, applicationPoolSchema.ProcessModelSchema.Username)
+	passwordKey := fmt.Sprintf("%s.0.%s", processModelKey, applicationPoolSchema.ProcessModelSchema.Password)
+	if !d.NewValueKnown(processModelKey) || !d.NewValueKnown(usernameKey) || !d.NewValueKnown(passwordKey) {
+		return nil
+	}
+
+	processModelList := d.Get(processModelKey).([]interface{})
+	if len(processModelList) == 0 || processModelList[0] == nil {
+		return nil
+	}
+
+	processModel := processModelList[0].(map[string]interface{})
+	identityType := processModel[applicationPoolSchema.ProcessModelSchema.IdentityType].(string)
+	username := processModel[applicationPoolSchema.ProcessModelSchema.Username].(string)
+	password := processModel[applicationPoolSchema.ProcessModelSchema.Password].(string)
+	if identityType != "SpecificUser" {
+		if len(username) > 0 || len(password) > 0 {
+			return fmt.Errorf("%q and %q can only be set when %q is 'SpecificUser'", usernameKey, passwordKey, applicationPoolSchema.ProcessModelSchema.IdentityType)
+		}
+
+		return nil
+	}
+
+	if len(username) == 0 {
+		return fmt.Errorf("%q is required when %q is 'SpecificUser'", usernameKey, applicationPoolSchema.ProcessModelSchema.IdentityType)
+	}
+
+	isManagedServiceAccount := strings.HasSuffix(username, "$")
+	if isManagedServiceAccount && len(password) > 0 {
+		return fmt.Errorf("%q must not be set for the group Managed Service Account %q", passwordKey, username)
+	}
+
+	if !isManagedServiceAccount && len(password) == 0 {
+		return fmt.Errorf("%q is required when %q is 'SpecificUser', unless %q is a group Managed Service Account ending with '$'", passwordKey, applicationPoolSchema.ProcessModelSchema.IdentityType, usernameKey)
+	}
+
+	return nil
+}
+
+// validateIdleTimeoutActionDiff checks the prerequisites of suspending the idle worker processes instead of terminating them
+func validateIdleTimeoutActionDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	processModelKey := applicationPoolSchema.ProcessModelSchema.Key
+	if !d.NewValueKnown(processModelKey) {
+		return nil
+	}
+
+	processModelList := d.Get(processModelKey).([]interface{})
+	if len(processModelList) == 0 || processModelList[0] == nil {
+		return nil
+	}
+
+	processModel := processModelList[0].(map[string]interface{})
+	if processModel[applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction].(string) != "Suspend" {
+		return nil
+	}
+
+	idleTimeoutActionKey := fmt.Sprintf("%s.0.%s", processModelKey, applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction)
+	if getDuration(processModel[applicationPoolSchema.ProcessModelSchema.IdleTimeout], time.Minute) == 0 {
+		idleTimeoutKey := fmt.Sprintf("%s.0.%s", processModelKey, applicationPoolSchema.ProcessModelSchema.IdleTimeout)
+		return fmt.Errorf("%q must be greater than 0 when %q is 'Suspend', otherwise the worker processes are never suspended", idleTimeoutKey, idleTimeoutActionKey)
+	}
+
+	// The host is only asked for its version when the process model changes, to keep the plans of unchanged pools offline
+	if !d.HasChange(processModelKey) {
+		return nil
+	}
+
+	client, span := startSpan(ctx, m, "iis_application_pool", "plan", d.Get(applicationPoolSchema.Name).(string))
+	defer span.End()
+
+	version, err := client.GetServerVersion()
+	if err != nil {
 		return err
 	}
-	if err = d.Set(applicationPoolSchema.RuntimeVersion, appPool.ManagedRuntimeVersion); err != nil {
+
+	if !version.AtLeast(8, 5) {
+		return fmt.Errorf("%q 'Suspend' requires IIS 8.5 or later, but the host runs %s", idleTimeoutActionKey, version)
+	}
+
+	return nil
+}
+
+// applyAppPoolState starts or stops the application pool and waits until it settles in the desired state
+func applyAppPoolState(ctx context.Context, client *agent.Client, name string, desiredState string, timeout time.Duration) error {
+	currentState, err := client.GetAppPoolState(name)
+	if err != nil {
 		return err
 	}
-	if err = d.Set(applicationPoolSchema.Enable32Bit, appPool.Enable32BitWin64); err != nil {
+
+	if currentState == desiredState {
+		return nil
+	}
+
+	pending := []string{"Starting"}
+	if desiredState == "Started" {
+		err = client.StartAppPool(name)
+	} else {
+		pending = []string{"Stopping"}
+		err = client.StopAppPool(name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to change the state of application pool '%s' to '%s': %w", name, desiredState, err)
+	}
+
+	stateChange := &retry.StateChangeConf{
+		Pending:    pending,
+		Target:     []string{desiredState},
+		Timeout:    timeout,
+		MinTimeout: 1 * time.Second,
+		Refresh: func() (interface{}, string, error) {
+			state, err := client.GetAppPoolState(name)
+			return state, state, err
+		},
+	}
+
+	if _, err = stateChange.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("application pool '%s' did not reach the '%s' state: %w", name, desiredState, err)
+	}
+
+	return nil
+}
+
+func validateEnvironmentVariablesDiff(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown(applicationPoolSchema.EnvironmentVariables) || !d.NewValueKnown(applicationPoolSchema.SensitiveEnvironmentVariables) {
+		return nil
+	}
+
+	environmentVariables := d.Get(applicationPoolSchema.EnvironmentVariables).(map[string]interface{})
+	for name := range d.Get(applicationPoolSchema.SensitiveEnvironmentVariables).(map[string]interface{}) {
+		for existingName := range environmentVariables {
+			if strings.EqualFold(name, existingName) {
+				return fmt.Errorf("environment variable %q is set in both %q and %q", name, applicationPoolSchema.EnvironmentVariables, applicationPoolSchema.SensitiveEnvironmentVariables)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recycleAppPool recycles the application pool and optionally waits until a new worker process replaces the previous ones
+func recycleAppPool(ctx context.Context, client *agent.Client, name string, waitForWorkerProcess bool, timeout time.Duration) error {
+	previousProcessIds, err := client.GetAppPoolWorkerProcessIds(name)
+	if err != nil {
 		return err
 	}
-	if err = d.Set(applicationPoolSchema.QueueLength, appPool.QueueLength); err != nil {
+
+	if err = client.RecycleAppPool(name); err != nil {
+		return fmt.Errorf("failed to recycle application pool '%s': %w", name, err)
+	}
+
+	if !waitForWorkerProcess {
+		return nil
+	}
+
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		processIds, err := client.GetAppPoolWorkerProcessIds(name)
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+
+		for _, processId := range processIds {
+			if !slices.Contains(previousProcessIds, processId) {
+				return nil
+			}
+		}
+
+		return retry.RetryableError(fmt.Errorf("application pool '%s' has no new worker process yet", name))
+	})
+}
+
+// releaseAppPool makes sure no web site or web application is left running in the application pool about to be deleted,
+// moving them to reassignTo when set
+func releaseAppPool(client *agent.Client, name string, reassignTo string, forceDelete bool) error {
+	usages, err := client.GetAppPoolUsages(name)
+	if err != nil {
 		return err
 	}
 
-	processModel := map[string]interface{}{
-		applicationPoolSchema.ProcessModelSchema.IdentityType:        appPool.ProcessModel.IdentityType,
-		applicationPoolSchema.ProcessModelSchema.Username:            appPool.ProcessModel.Username,
-		applicationPoolSchema.ProcessModelSchema.LoadUserProfile:     appPool.ProcessModel.LoadUserProfile,
-		applicationPoolSchema.ProcessModelSchema.IdleTimeout:         appPool.ProcessModel.IdleTimeout,
-		applicationPoolSchema.ProcessModelSchema.IdleTimeoutAction:   appPool.ProcessModel.IdleTimeoutAction,
-		applicationPoolSchema.ProcessModelSchema.MaxProcesses:        appPool.ProcessModel.MaxProcesses,
-		applicationPoolSchema.ProcessModelSchema.PingingEnabled:      appPool.ProcessModel.PingingEnabled,
-		applicationPoolSchema.ProcessModelSchema.PingingInterval:     appPool.ProcessModel.PingInterval,
-		applicationPoolSchema.ProcessModelSchema.PingingResponseTime: appPool.ProcessModel.PingResponseTime,
-		applicationPoolSchema.ProcessModelSchema.StartupTimeLimit:    appPool.ProcessModel.StartupTimeLimit,
-		applicationPoolSchema.ProcessModelSchema.ShutdownTimeLimit:   appPool.ProcessModel.ShutdownTimeLimit,
-	}
-
-	err = d.Set(applicationPoolSchema.ProcessModelSchema.Key, []interface{}{processModel})
-	return err
+	if len(usages) == 0 {
+		return nil
+	}
+
+	if reassignTo != "" {
+		if strings.EqualFold(reassignTo, name) {
+			return fmt.Errorf("application pool '%s' cannot be reassigned to itself", name)
+		}
+
+		if err = validateAppPoolExists(client, reassignTo); err != nil {
+			return err
+		}
+
+		return client.ReassignAppPoolUsages(usages, reassignTo)
+	}
+
+	if forceDelete {
+		return nil
+	}
+
+	var names []string
+	for _, usage := range usages {
+		names = append(names, usage.String())
+	}
+
+	return fmt.Errorf("application pool '%s' is still used by %s. Set reassign_to to move them to another application pool, or force_delete to delete it anyway", name, strings.Join(names, ", "))
 }
 
 func validateAppPoolExists(client *agent.Client, appPoolName string) error {
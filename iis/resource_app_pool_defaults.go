@@ -0,0 +1,87 @@
+package iis
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+// applicationPoolDefaultsAttributes are the attributes of iis_application_pool that also exist on applicationPoolDefaults
+var applicationPoolDefaultsAttributes = []string{
+	applicationPoolSchema.AutoStart,
+	applicationPoolSchema.StartMode,
+	applicationPoolSchema.PipelineMode,
+	applicationPoolSchema.RuntimeVersion,
+	applicationPoolSchema.Enable32Bit,
+	applicationPoolSchema.QueueLength,
+	applicationPoolSchema.ProcessModelSchema.Key,
+	applicationPoolSchema.CPUSchema.Key,
+	applicationPoolSchema.RecyclingSchema.Key,
+	applicationPoolSchema.FailureSchema.Key,
+}
+
+func resourceApplicationPoolDefaults() *schema.Resource {
+	appPoolSchema := resourceApplicationPool().Schema
+	defaultsSchema := map[string]*schema.Schema{}
+	for _, key := range applicationPoolDefaultsAttributes {
+		defaultsSchema[key] = appPoolSchema[key]
+	}
+
+	return &schema.Resource{
+		Description: "The server-wide defaults inherited by every application pool which does not override them. A single instance should exist per host, and destroying it restores the stock IIS defaults",
+
+		CreateContext: resourceApplicationPoolDefaultsUpdate,
+		ReadContext:   resourceApplicationPoolDefaultsRead,
+		UpdateContext: resourceApplicationPoolDefaultsUpdate,
+		DeleteContext: resourceApplicationPoolDefaultsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: defaultsSchema,
+	}
+}
+
+func resourceApplicationPoolDefaultsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_pool_defaults", "read", d.Id())
+	defer span.End()
+
+	appPool, err := client.GetAppPoolDefaults()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	attributes := flattenApplicationPool(*appPool, configuredAppPoolPassword(d))
+	for _, key := range applicationPoolDefaultsAttributes {
+		if err = d.Set(key, attributes[key]); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+func resourceApplicationPoolDefaultsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_pool_defaults", "update", d.Id())
+	defer span.End()
+
+	appPool := mapToApplicationPoolSettings(d)
+	if err := client.UpdateAppPoolDefaults(appPool); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
+
+func resourceApplicationPoolDefaultsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_pool_defaults", "delete", d.Id())
+	defer span.End()
+
+	if err := client.UpdateAppPoolDefaults(agent.StockApplicationPoolDefaults); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
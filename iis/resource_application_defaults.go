@@ -0,0 +1,112 @@
+package iis
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func resourceApplicationDefaults() *schema.Resource {
+	return &schema.Resource{
+		Description: "The server-wide defaults inherited by every web application which does not override them. A single instance should exist per host, and destroying it restores the stock IIS defaults",
+
+		CreateContext: resourceApplicationDefaultsUpdate,
+		ReadContext:   resourceApplicationDefaultsRead,
+		UpdateContext: resourceApplicationDefaultsUpdate,
+		DeleteContext: resourceApplicationDefaultsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			applicationDefaultsSchema.ApplicationPoolName: {
+				Description: "The application pool used by the web applications which do not set one",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "DefaultAppPool",
+			},
+			applicationDefaultsSchema.EnabledProtocols: {
+				Description: "Comma separated list of the protocols enabled for the web applications, e.g. http,net.tcp",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "http",
+			},
+			applicationDefaultsSchema.PreloadEnabled: {
+				Description: "If true, the web applications are loaded when their application pool starts, without waiting for the first request. Requires the AlwaysRunning start mode on the application pool",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			applicationDefaultsSchema.ServiceAutoStartEnabled: {
+				Description: "If true, the service auto start provider is called when the application pool starts",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			applicationDefaultsSchema.ServiceAutoStartProvider: {
+				Description: "The name of the service auto start provider, as declared in serviceAutoStartProviders",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+		},
+	}
+}
+
+func resourceApplicationDefaultsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_defaults", "read", d.Id())
+	defer span.End()
+
+	defaults, err := client.GetApplicationDefaults()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	attributes := map[string]interface{}{
+		applicationDefaultsSchema.ApplicationPoolName:      defaults.ApplicationPoolName,
+		applicationDefaultsSchema.EnabledProtocols:         defaults.EnabledProtocols,
+		applicationDefaultsSchema.PreloadEnabled:           defaults.PreloadEnabled,
+		applicationDefaultsSchema.ServiceAutoStartEnabled:  defaults.ServiceAutoStartEnabled,
+		applicationDefaultsSchema.ServiceAutoStartProvider: defaults.ServiceAutoStartProvider,
+	}
+
+	for key, value := range attributes {
+		if err = d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+func resourceApplicationDefaultsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_defaults", "update", d.Id())
+	defer span.End()
+
+	defaults := agent.ApplicationDefaults{
+		ApplicationPoolName:      d.Get(applicationDefaultsSchema.ApplicationPoolName).(string),
+		EnabledProtocols:         d.Get(applicationDefaultsSchema.EnabledProtocols).(string),
+		PreloadEnabled:           d.Get(applicationDefaultsSchema.PreloadEnabled).(bool),
+		ServiceAutoStartEnabled:  d.Get(applicationDefaultsSchema.ServiceAutoStartEnabled).(bool),
+		ServiceAutoStartProvider: d.Get(applicationDefaultsSchema.ServiceAutoStartProvider).(string),
+	}
+
+	if err := client.UpdateApplicationDefaults(defaults); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
+
+func resourceApplicationDefaultsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_application_defaults", "delete", d.Id())
+	defer span.End()
+
+	if err := client.UpdateApplicationDefaults(agent.StockApplicationDefaults); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
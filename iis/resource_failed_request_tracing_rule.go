@@ -0,0 +1,223 @@
+package iis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func resourceFailedRequestTracingRule() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A rule of the failed request tracing (system.webServer/tracing/traceFailedRequests) of a site, deciding which failed requests are traced and with which providers. The tracing itself is enabled by the trace_failed_requests_logging block of the iis_web_site",
+		CreateContext: resourceFailedRequestTracingRuleCreate,
+		ReadContext:   resourceFailedRequestTracingRuleRead,
+		UpdateContext: resourceFailedRequestTracingRuleUpdate,
+		DeleteContext: resourceFailedRequestTracingRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importFailedRequestTracingRuleState,
+		},
+		Schema: map[string]*schema.Schema{
+			failedRequestTracingRuleSchema.WebSiteName: {
+				Description: "The name of the site the rule belongs to",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			failedRequestTracingRuleSchema.Path: {
+				Description: "The content the rule applies to, e.g. * for all the content or *.aspx",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			failedRequestTracingRuleSchema.StatusCodes: {
+				Description:      "The status codes which trigger the tracing, e.g. 500-599,404.2. Empty to not trace on status codes",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "",
+				ValidateDiagFunc: isValid(regexp.MustCompile(`^(\d{3}(\.\d+)?(-\d{3})?(,\s*\d{3}(\.\d+)?(-\d{3})?)*)?$`)),
+			},
+			failedRequestTracingRuleSchema.TimeTaken: {
+				Description:      "The time, in seconds or as a duration such as \"30s\", after which a request still running is traced. 0 to not trace on time taken",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "0",
+				ValidateDiagFunc: isDurationInBetween(time.Second, 0, noMaximumDuration),
+				DiffSuppressFunc: suppressEquivalentDuration(time.Second),
+			},
+			failedRequestTracingRuleSchema.Verbosity: {
+				Description:      "The verbosity of the trace events which trigger the tracing: Ignore, CriticalError, Error or Warning",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "Ignore",
+				ValidateDiagFunc: validateAllowedValues([]string{"Ignore", "CriticalError", "Error", "Warning"}),
+			},
+			failedRequestTracingRuleSchema.TraceAreaSchema.Key: {
+				Description: "The trace providers, and their areas, written to the trace files",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: traceAreaSchema,
+				},
+			},
+		},
+	}
+}
+
+var traceAreaSchema = map[string]*schema.Schema{
+	failedRequestTracingRuleSchema.TraceAreaSchema.Provider: {
+		Description: "The name of the trace provider, as declared in traceProviderDefinitions: WWW Server, ASP, ASPNET or ISAPI Extension",
+		Type:        schema.TypeString,
+		Required:    true,
+	},
+	failedRequestTracingRuleSchema.TraceAreaSchema.Areas: {
+		Description: "Comma separated list of the areas of the provider to trace, e.g. Authentication,Security,Module for WWW Server. Empty to trace all of them",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+	failedRequestTracingRuleSchema.TraceAreaSchema.Verbosity: {
+		Description:      "The verbosity of the events written: General, CriticalError, Error, Warning, Information or Verbose",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "Verbose",
+		ValidateDiagFunc: validateAllowedValues([]string{"General", "CriticalError", "Error", "Warning", "Information", "Verbose"}),
+	},
+}
+
+func resourceFailedRequestTracingRuleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_failed_request_tracing_rule", "create", d.Get(failedRequestTracingRuleSchema.Path).(string))
+	defer span.End()
+
+	rule := mapToFailedRequestTracingRule(d)
+	if _, err := client.GetWebSite(rule.WebSiteName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := client.PutFailedRequestTracingRule(rule); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s_%s", rule.WebSiteName, rule.Path))
+	return nil
+}
+
+func resourceFailedRequestTracingRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_failed_request_tracing_rule", "read", d.Get(failedRequestTracingRuleSchema.Path).(string))
+	defer span.End()
+
+	rule, err := client.GetFailedRequestTracingRule(d.Get(failedRequestTracingRuleSchema.WebSiteName).(string), d.Get(failedRequestTracingRuleSchema.Path).(string))
+	if err != nil {
+		d.SetId("")
+		return diag.FromErr(err)
+	}
+
+	if err = mapFailedRequestTracingRuleToResourceData(*rule, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFailedRequestTracingRuleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_failed_request_tracing_rule", "update", d.Get(failedRequestTracingRuleSchema.Path).(string))
+	defer span.End()
+
+	if err := client.PutFailedRequestTracingRule(mapToFailedRequestTracingRule(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFailedRequestTracingRuleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_failed_request_tracing_rule", "delete", d.Get(failedRequestTracingRuleSchema.Path).(string))
+	defer span.End()
+
+	err := client.DeleteFailedRequestTracingRule(d.Get(failedRequestTracingRuleSchema.WebSiteName).(string), d.Get(failedRequestTracingRuleSchema.Path).(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func importFailedRequestTracingRuleState(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client, span := startSpan(ctx, meta, "iis_failed_request_tracing_rule", "import", d.Id())
+	defer span.End()
+
+	// Site names are more likely to contain underscores than the paths, which are file name patterns
+	separator := strings.LastIndex(d.Id(), "_")
+	if separator < 1 {
+		return nil, errors.New("provided id is invalid, please provide the id in the following format: '{web_site_name}_{path}'")
+	}
+
+	rule, err := client.GetFailedRequestTracingRule(d.Id()[:separator], d.Id()[separator+1:])
+	if err != nil {
+		d.SetId("")
+		return nil, err
+	}
+
+	if err = mapFailedRequestTracingRuleToResourceData(*rule, d); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func mapToFailedRequestTracingRule(d *schema.ResourceData) agent.FailedRequestTracingRule {
+	traceAreas := []agent.TraceArea{}
+	for _, value := range d.Get(failedRequestTracingRuleSchema.TraceAreaSchema.Key).([]interface{}) {
+		traceArea := value.(map[string]interface{})
+		traceAreas = append(traceAreas, agent.TraceArea{
+			Provider:  traceArea[failedRequestTracingRuleSchema.TraceAreaSchema.Provider].(string),
+			Areas:     traceArea[failedRequestTracingRuleSchema.TraceAreaSchema.Areas].(string),
+			Verbosity: traceArea[failedRequestTracingRuleSchema.TraceAreaSchema.Verbosity].(string),
+		})
+	}
+
+	return agent.FailedRequestTracingRule{
+		WebSiteName: d.Get(failedRequestTracingRuleSchema.WebSiteName).(string),
+		Path:        d.Get(failedRequestTracingRuleSchema.Path).(string),
+		StatusCodes: d.Get(failedRequestTracingRuleSchema.StatusCodes).(string),
+		TimeTaken:   getDuration(d.Get(failedRequestTracingRuleSchema.TimeTaken), time.Second),
+		Verbosity:   d.Get(failedRequestTracingRuleSchema.Verbosity).(string),
+		TraceAreas:  traceAreas,
+	}
+}
+
+func mapFailedRequestTracingRuleToResourceData(rule agent.FailedRequestTracingRule, d *schema.ResourceData) error {
+	d.SetId(fmt.Sprintf("%s_%s", rule.WebSiteName, rule.Path))
+
+	var traceAreas []interface{}
+	for _, traceArea := range rule.TraceAreas {
+		traceAreas = append(traceAreas, map[string]interface{}{
+			failedRequestTracingRuleSchema.TraceAreaSchema.Provider:  traceArea.Provider,
+			failedRequestTracingRuleSchema.TraceAreaSchema.Areas:     traceArea.Areas,
+			failedRequestTracingRuleSchema.TraceAreaSchema.Verbosity: traceArea.Verbosity,
+		})
+	}
+
+	values := map[string]interface{}{
+		failedRequestTracingRuleSchema.WebSiteName:         rule.WebSiteName,
+		failedRequestTracingRuleSchema.Path:                rule.Path,
+		failedRequestTracingRuleSchema.StatusCodes:         rule.StatusCodes,
+		failedRequestTracingRuleSchema.TimeTaken:           formatDuration(rule.TimeTaken, time.Second),
+		failedRequestTracingRuleSchema.Verbosity:           rule.Verbosity,
+		failedRequestTracingRuleSchema.TraceAreaSchema.Key: traceAreas,
+	}
+
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package iis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func resourceSiteDefaults() *schema.Resource {
+	return &schema.Resource{
+		Description: "The server-wide defaults inherited by every web site which does not override them. A single instance should exist per host, and destroying it restores the stock IIS defaults",
+
+		CreateContext: resourceSiteDefaultsUpdate,
+		ReadContext:   resourceSiteDefaultsRead,
+		UpdateContext: resourceSiteDefaultsUpdate,
+		DeleteContext: resourceSiteDefaultsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			siteDefaultsSchema.AutoStart: {
+				Description: "If true, the web sites are started automatically when IIS is started",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			siteDefaultsSchema.LimitsSchema.Key: {
+				Description: "Defines the bandwidth, connection and URL limits of the web sites, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteLimitsSchema,
+				},
+			},
+			siteDefaultsSchema.LogFileSchema.Key: {
+				Description: "Defines where and how the requests of the web sites are logged, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteLogFileSchema,
+				},
+			},
+			siteDefaultsSchema.TracingSchema.Key: {
+				Description: "Defines where the traces of the failed requests of the web sites are written, left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteTracingSchema,
+				},
+			},
+		},
+	}
+}
+
+func resourceSiteDefaultsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_site_defaults", "read", d.Id())
+	defer span.End()
+
+	defaults, err := client.GetSiteDefaults()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set(siteDefaultsSchema.AutoStart, defaults.AutoStart); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set(siteDefaultsSchema.LimitsSchema.Key, flattenLimits(*defaults.Limits)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set(siteDefaultsSchema.LogFileSchema.Key, flattenLogFile(*defaults.LogFile)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set(siteDefaultsSchema.TracingSchema.Key, flattenTraceFailedRequestsLogging(*defaults.TraceFailedRequestsLogging)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceSiteDefaultsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_site_defaults", "update", d.Id())
+	defer span.End()
+
+	// The blocks which are not configured are left as they are on the host
+	defaults := agent.SiteDefaults{
+		AutoStart: d.Get(siteDefaultsSchema.AutoStart).(bool),
+	}
+
+	if isBlockConfigured(d, siteDefaultsSchema.LimitsSchema.Key) {
+		limits := mapToLimits(getBlockOrDefaults(d, siteDefaultsSchema.LimitsSchema.Key, webSiteLimitsSchema))
+		defaults.Limits = &limits
+	}
+
+	if isBlockConfigured(d, siteDefaultsSchema.LogFileSchema.Key) {
+		logFile := mapToLogFile(getBlockOrDefaults(d, siteDefaultsSchema.LogFileSchema.Key, webSiteLogFileSchema))
+		defaults.LogFile = &logFile
+	}
+
+	if isBlockConfigured(d, siteDefaultsSchema.TracingSchema.Key) {
+		tracing := mapToTraceFailedRequestsLogging(getBlockOrDefaults(d, siteDefaultsSchema.TracingSchema.Key, webSiteTracingSchema))
+		defaults.TraceFailedRequestsLogging = &tracing
+	}
+
+	if err := client.UpdateSiteDefaults(defaults); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(client.Host())
+	return nil
+}
+
+func resourceSiteDefaultsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, span := startSpan(ctx, m, "iis_site_defaults", "delete", d.Id())
+	defer span.End()
+
+	if err := client.UpdateSiteDefaults(agent.StockSiteDefaults); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func mapToLogFile(logFileResource map[string]interface{}) agent.LogFile {
+	// The flags are computed sets, missing from the defaults of an unconfigured block
+	logExtFileFlags := agent.FlagList{}
+	if flags, ok := logFileResource[webSiteSchema.LogFileSchema.LogExtFileFlags].(*schema.Set); ok {
+		for _, flag := range flags.List() {
+			logExtFileFlags = append(logExtFileFlags, flag.(string))
+		}
+	}
+
+	logTargetW3C := agent.FlagList{}
+	if flags, ok := logFileResource[webSiteSchema.LogFileSchema.LogTargetW3C].(*schema.Set); ok {
+		for _, flag := range flags.List() {
+			logTargetW3C = append(logTargetW3C, flag.(string))
+		}
+	}
+
+	customFields := []agent.LogCustomField{}
+	for _, value := range logFileResource[webSiteSchema.LogFileSchema.CustomFieldSchema.Key].([]interface{}) {
+		field := value.(map[string]interface{})
+		customFields = append(customFields, agent.LogCustomField{
+			LogFieldName: field[webSiteSchema.LogFileSchema.CustomFieldSchema.LogFieldName].(string),
+			SourceName:   field[webSiteSchema.LogFileSchema.CustomFieldSchema.SourceName].(string),
+			SourceType:   field[webSiteSchema.LogFileSchema.CustomFieldSchema.SourceType].(string),
+		})
+	}
+
+	return agent.LogFile{
+		Enabled:           logFileResource[webSiteSchema.LogFileSchema.Enabled].(bool),
+		LogFormat:         logFileResource[webSiteSchema.LogFileSchema.LogFormat].(string),
+		Directory:         logFileResource[webSiteSchema.LogFileSchema.Directory].(string),
+		Period:            logFileResource[webSiteSchema.LogFileSchema.Period].(string),
+		TruncateSize:      getInt64(logFileResource[webSiteSchema.LogFileSchema.TruncateSize]),
+		LocalTimeRollover: logFileResource[webSiteSchema.LogFileSchema.LocalTimeRollover].(bool),
+		LogExtFileFlags:   logExtFileFlags,
+		LogTargetW3C:      logTargetW3C,
+		CustomFields:      customFields,
+	}
+}
+
+func flattenLogFile(logFile agent.LogFile) []interface{} {
+	var logExtFileFlags []interface{}
+	for _, flag := range logFile.LogExtFileFlags {
+		logExtFileFlags = append(logExtFileFlags, flag)
+	}
+
+	var logTargetW3C []interface{}
+	for _, flag := range logFile.LogTargetW3C {
+		logTargetW3C = append(logTargetW3C, flag)
+	}
+
+	var customFields []interface{}
+	for _, field := range logFile.CustomFields {
+		customFields = append(customFields, map[string]interface{}{
+			webSiteSchema.LogFileSchema.CustomFieldSchema.LogFieldName: field.LogFieldName,
+			webSiteSchema.LogFileSchema.CustomFieldSchema.SourceName:   field.SourceName,
+			webSiteSchema.LogFileSchema.CustomFieldSchema.SourceType:   field.SourceType,
+		})
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			webSiteSchema.LogFileSchema.Enabled:               logFile.Enabled,
+			webSiteSchema.LogFileSchema.LogFormat:             logFile.LogFormat,
+			webSiteSchema.LogFileSchema.Directory:             logFile.Directory,
+			webSiteSchema.LogFileSchema.Period:                logFile.Period,
+			webSiteSchema.LogFileSchema.TruncateSize:          strconv.FormatInt(logFile.TruncateSize, 10),
+			webSiteSchema.LogFileSchema.LocalTimeRollover:     logFile.LocalTimeRollover,
+			webSiteSchema.LogFileSchema.LogExtFileFlags:       logExtFileFlags,
+			webSiteSchema.LogFileSchema.LogTargetW3C:          logTargetW3C,
+			webSiteSchema.LogFileSchema.CustomFieldSchema.Key: customFields,
+		},
+	}
+}
@@ -18,6 +18,7 @@ func resourceWebApplication() *schema.Resource {
 		ReadContext:   resourceWebApplicationRead,
 		UpdateContext: resourceWebApplicationUpdate,
 		DeleteContext: resourceWebApplicationDelete,
+		CustomizeDiff: customizeWebApplicationDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: importWebApplicationState,
 		},
@@ -55,12 +56,49 @@ func resourceWebApplication() *schema.Resource {
 				Required:         true,
 				ValidateDiagFunc: isValidPath(true),
 			},
+			physicalPathSchema.CreatePhysicalPath:   createPhysicalPathAttribute,
+			physicalPathSchema.PermissionSchema.Key: physicalPathPermissionAttribute,
+			physicalPathSchema.OnDestroy:            onDestroyAttribute,
+			webAppSchema.Username: {
+				Description: "Username for the user identity that should be impersonated when accessing the physical path for the virtual directory",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+			webAppSchema.Password: {
+				Description:      "Password for the user identity that should be impersonated when accessing the physical path for the virtual directory. It is write-only: never read from the host nor kept in the state, so change password_version to apply a new one",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				Default:          "",
+				DiffSuppressFunc: suppressWriteOnlyDiff,
+			},
+			webAppSchema.PasswordVersion: {
+				Description: "Arbitrary value that, when changed, writes the password again. For example, a hash of the password or the version of the secret holding it",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+			},
+			webAppSchema.LogonMethod: {
+				Description:      "The logon type used to impersonate the physical path credentials of the virtual directory: Interactive, Batch, Network or ClearText",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "ClearText",
+				ValidateDiagFunc: validateAllowedValues(agent.LogonMethods),
+			},
+			webAppSchema.DeletionProtection: {
+				Description: "If true, the web application cannot be deleted (nor replaced) until this is set back to false and applied",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
 
 func resourceWebApplicationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_application", "read", d.Get(webAppSchema.Name).(string))
+	defer span.End()
 
 	site := d.Get(webAppSchema.Site).(string)
 	name := d.Get(webAppSchema.Name).(string)
@@ -75,7 +113,8 @@ func resourceWebApplicationRead(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceWebApplicationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_application", "create", d.Get(webAppSchema.Name).(string))
+	defer span.End()
 
 	if d.HasChange(webAppSchema.ApplicationPoolName) {
 		appPoolName := d.Get(webAppSchema.ApplicationPoolName).(string)
@@ -92,11 +131,17 @@ func resourceWebApplicationCreate(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	d.SetId(fmt.Sprintf("%s_%s", webApplication.Site, webApplication.Name))
+	// The password only lives in the configuration, as it is write-only
+	if err = d.Set(webAppSchema.Password, ""); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
 func resourceWebApplicationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_application", "update", d.Get(webAppSchema.Name).(string))
+	defer span.End()
 
 	if d.HasChange(webAppSchema.ApplicationPoolName) {
 		appPoolName := d.Get(webAppSchema.ApplicationPoolName).(string)
@@ -116,21 +161,32 @@ func resourceWebApplicationUpdate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceWebApplicationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_application", "delete", d.Get(webAppSchema.Name).(string))
+	defer span.End()
 
 	site := d.Get(webAppSchema.Site).(string)
 	name := d.Get(webAppSchema.Name).(string)
+	if d.Get(webAppSchema.DeletionProtection).(bool) {
+		return diag.Errorf("web application '%s/%s' has deletion_protection enabled, set it to false and apply before deleting it", site, name)
+	}
+
 	err := client.DeleteWebApplication(site, name)
 	if err != nil {
 		d.SetId("")
 		return diag.FromErr(err)
 	}
 
-	return nil
+	return destroyPhysicalPath(client, d, webAppSchema.PhysicalPath)
+}
+
+func customizeWebApplicationDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	return validatePhysicalPathReplacementDiff(d, webAppSchema.PhysicalPath, webAppSchema.Name, webAppSchema.Site)
 }
 
 func importWebApplicationState(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	client := meta.(*agent.Client)
+	client, span := startSpan(ctx, meta, "iis_web_application", "import", d.Id())
+	defer span.End()
+
 	id := d.Id()
 	siteAndName := strings.Split(id, "_")
 	if len(siteAndName) < 2 {
@@ -151,32 +207,46 @@ func importWebApplicationState(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func mapWebApplicationToResourceData(webApplication agent.WebApplication, d *schema.ResourceData) error {
-	var err error
 	d.SetId(webApplication.Id)
-	if err = d.Set(webAppSchema.Name, webApplication.Name); err != nil {
-		return err
-	}
-	if err = d.Set(webAppSchema.Path, webApplication.Path); err != nil {
-		return err
-	}
-	if err = d.Set(webAppSchema.PhysicalPath, webApplication.PhysicalPath); err != nil {
-		return err
-	}
-	if err = d.Set(webAppSchema.Site, webApplication.Site); err != nil {
-		return err
-	}
-	if err = d.Set(webAppSchema.ApplicationPoolName, webApplication.ApplicationPoolName); err != nil {
-		return err
+	for key, value := range flattenWebApplication(webApplication) {
+		if err := d.Set(key, value); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return d.Set(webAppSchema.Password, "")
+}
+
+// flattenWebApplication maps the web application into the attributes shared by the resource and the data sources
+func flattenWebApplication(webApplication agent.WebApplication) map[string]interface{} {
+	return map[string]interface{}{
+		webAppSchema.Name:                webApplication.Name,
+		webAppSchema.Path:                webApplication.Path,
+		webAppSchema.PhysicalPath:        webApplication.PhysicalPath,
+		webAppSchema.Site:                webApplication.Site,
+		webAppSchema.ApplicationPoolName: webApplication.ApplicationPoolName,
+		webAppSchema.Username:            webApplication.Username,
+		webAppSchema.LogonMethod:         webApplication.LogonMethod,
+	}
 }
 
 func mapToWebApplication(d *schema.ResourceData) agent.WebApplication {
+	// The password is not in the state to compare it with, so it is written on creation and when its version or the user changes
+	var password *string
+	if d.Id() == "" || d.HasChanges(webAppSchema.Username, webAppSchema.PasswordVersion) {
+		value := getConfiguredString(d, webAppSchema.Password)
+		password = &value
+	}
+
 	return agent.WebApplication{
 		Name:                d.Get(webAppSchema.Name).(string),
 		Site:                d.Get(webAppSchema.Site).(string),
 		ApplicationPoolName: d.Get(webAppSchema.ApplicationPoolName).(string),
 		PhysicalPath:        d.Get(webAppSchema.PhysicalPath).(string),
+		Username:            d.Get(webAppSchema.Username).(string),
+		Password:            password,
+		LogonMethod:         d.Get(webAppSchema.LogonMethod).(string),
+
+		PhysicalPathProvisioning: expandPhysicalPathProvisioning(d, webAppSchema.PhysicalPath),
 	}
 }
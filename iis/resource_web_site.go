@@ -2,8 +2,15 @@ package iis
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
@@ -15,19 +22,39 @@ func resourceWebsite() *schema.Resource {
 		ReadContext:   resourceWebsiteRead,
 		UpdateContext: resourceWebsiteUpdate,
 		DeleteContext: resourceWebsiteDelete,
+		CustomizeDiff: customizeWebSiteDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: importWebSiteState,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			webSiteSchema.Id: {
 				Description: "An unique numeric identifier for the site. This identifier is used in directory names for log files and trace files",
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			webSiteSchema.SiteId: {
+				Description:      "The numeric identifier of the site, used in the names of the log and trace directories (W3SVC<id>). The next free one is picked when not set, and changing it restarts the site in place",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: isUint32InBetween(1, math.MaxUint32),
+			},
 			webSiteSchema.State: {
-				Description: "The current state of the site",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:      "The desired state of the site. The site is started or stopped in place, and stopping it outside of Terraform is reported as drift",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "Started",
+				ValidateDiagFunc: validateAllowedValues([]string{"Started", "Stopped"}),
+			},
+			webSiteSchema.AutoStart: {
+				Description: "If true, the site is started automatically when IIS is started",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
 			},
 			webSiteSchema.Name: {
 				Description: "An unique name for the associated site",
@@ -46,6 +73,9 @@ func resourceWebsite() *schema.Resource {
 				Required:         true,
 				ValidateDiagFunc: isValidPath(true),
 			},
+			physicalPathSchema.CreatePhysicalPath:   createPhysicalPathAttribute,
+			physicalPathSchema.PermissionSchema.Key: physicalPathPermissionAttribute,
+			physicalPathSchema.OnDestroy:            onDestroyAttribute,
 			webSiteSchema.Username: {
 				Description: "Username for the user identity that should be impersonated when accessing the physical path for the virtual directory",
 				Type:        schema.TypeString,
@@ -53,14 +83,52 @@ func resourceWebsite() *schema.Resource {
 				Default:     "",
 			},
 			webSiteSchema.Password: {
-				Description: "Password for the user identity that should be impersonated when accessing the physical path for the virtual directory",
+				Description:      "Password for the user identity that should be impersonated when accessing the physical path for the virtual directory. It is write-only: never read from the host nor kept in the state, so change password_version to apply a new one",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				Default:          "",
+				DiffSuppressFunc: suppressWriteOnlyDiff,
+			},
+			webSiteSchema.PasswordVersion: {
+				Description: "Arbitrary value that, when changed, writes the password again. For example, a hash of the password or the version of the secret holding it",
 				Type:        schema.TypeString,
 				Optional:    true,
-				Sensitive:   true,
 				Default:     "",
 			},
+			webSiteSchema.LogonMethod: {
+				Description:      "The logon type used to impersonate the physical path credentials: Interactive, Batch, Network or ClearText",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "ClearText",
+				ValidateDiagFunc: validateAllowedValues(agent.LogonMethods),
+			},
+			webSiteSchema.RecycleTriggers: {
+				Description: "Arbitrary map of values that, when changed, restarts the site in place. For example, the version of the deployed content",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			webSiteSchema.DeletionProtection: {
+				Description: "If true, the site cannot be deleted (nor replaced) until this is set back to false and applied",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			webSiteSchema.EnabledProtocols: {
+				Description: "The protocols the requests can use to access the site, e.g. http and net.tcp. Left as configured on the host when not set",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateAllowedValues(bindingProtocols),
+				},
+			},
 			webSiteSchema.BindingSchema.Key: {
-				Description: "An HTTP binding is a combination of IP address, port and host name (the host name can be a domain name). HTTP.sys listens on the IP/port for incoming requests",
+				Description: "An HTTP binding is a combination of IP address, port and host name (the host name can be a domain name). HTTP.sys listens on the IP/port for incoming requests. A binding moved from one site to another has to be removed from the first site in a prior apply",
 				Type:        schema.TypeSet,
 				Optional:    true,
 				MinItems:    1,
@@ -68,23 +136,64 @@ func resourceWebsite() *schema.Resource {
 					Schema: webSiteBindingsSchema,
 				},
 			},
+			webSiteSchema.LogFileSchema.Key: {
+				Description: "Defines where and how the requests of the site are logged. The site inherits the iis_site_defaults when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteLogFileSchema,
+				},
+			},
+			webSiteSchema.HstsSchema.Key: {
+				Description: "Defines the HTTP Strict Transport Security of the site. Requires IIS 10 on Windows Server version 1709 or later, and is left as configured on the host when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteHstsSchema,
+				},
+			},
+			webSiteSchema.TracingSchema.Key: {
+				Description: "Defines where the traces of the failed requests are written. The site inherits the trace_failed_requests_logging block of the iis_site_defaults when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteTracingSchema,
+				},
+			},
+			webSiteSchema.LimitsSchema.Key: {
+				Description: "Defines the bandwidth, connection and URL limits of the site. The site inherits the iis_site_defaults when not set",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: webSiteLimitsSchema,
+				},
+			},
 		},
 	}
 }
 
 var webSiteBindingsSchema = map[string]*schema.Schema{
 	webSiteSchema.BindingSchema.Protocol: {
-		Description:      "Use HTTP if you want the website to have an HTTP binding, or select HTTPS if you want the website to have a Secure Sockets Layer (SSL) binding",
+		Description:      "Use HTTP if you want the website to have an HTTP binding, or select HTTPS if you want the website to have a Secure Sockets Layer (SSL) binding. The ftp and WAS protocols (net.tcp, net.pipe, net.msmq and msmq.formatname) are also supported",
 		Type:             schema.TypeString,
 		Optional:         true,
 		Default:          "http",
-		ValidateDiagFunc: validateAllowedValues([]string{"http", "https"}),
+		ValidateDiagFunc: validateAllowedValues(bindingProtocols),
 	},
 	webSiteSchema.BindingSchema.Ip: {
-		Description: "An IP address that users can use to access this site",
-		Type:        schema.TypeString,
-		Optional:    true,
-		Default:     "*",
+		Description:      "An IP address that users can use to access this site, * for all the unassigned addresses. IPv6 addresses are written without brackets, e.g. ::1",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "*",
+		ValidateDiagFunc: isValidBindingIp(),
 	},
 	webSiteSchema.BindingSchema.Port: {
 		Description:      "The port on which HTTP.sys must listen for requests made to this website",
@@ -99,10 +208,223 @@ var webSiteBindingsSchema = map[string]*schema.Schema{
 		Optional:    true,
 		Default:     "",
 	},
+	webSiteSchema.BindingSchema.BindingInformation: {
+		Description: "The binding information of the WAS protocols, e.g. 808:* for net.tcp or * for net.pipe. The ip, port and host_header are ignored for those protocols",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "",
+	},
+	webSiteSchema.BindingSchema.CertificateThumbprint: {
+		Description:      "The SHA-1 thumbprint of the certificate bound to an https binding, in upper case. Not needed when the certificate comes from the Central Certificate Store",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "",
+		ValidateDiagFunc: isValid(regexp.MustCompile(`^([0-9A-F]{40})?$`)),
+	},
+	webSiteSchema.BindingSchema.CertificateStoreName: {
+		Description: "The name of the local machine certificate store containing the certificate, e.g. My or WebHosting",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "My",
+	},
+	webSiteSchema.BindingSchema.SslFlags: {
+		Description: "The SSL flags of an https binding: Sni, CentralCertStore, DisableHTTP2, DisableOCSPStapling, DisableQUIC, DisableTLS13 and DisableLegacyTLS",
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validateAllowedValues(sslFlags),
+		},
+	},
+}
+
+var bindingProtocols = []string{"http", "https", "ftp", "net.tcp", "net.pipe", "net.msmq", "msmq.formatname"}
+
+var sslFlags = []string{"Sni", "CentralCertStore", "DisableHTTP2", "DisableOCSPStapling", "DisableQUIC", "DisableTLS13", "DisableLegacyTLS"}
+
+var webSiteLimitsSchema = map[string]*schema.Schema{
+	webSiteSchema.LimitsSchema.MaxBandwidth: {
+		Description:      "The maximum network bandwidth, in bytes per second, used by the site. 4294967295 means unlimited",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "4294967295",
+		ValidateDiagFunc: isUint32InBetween(1024, math.MaxUint32),
+	},
+	webSiteSchema.LimitsSchema.MaxConnections: {
+		Description:      "The maximum number of simultaneous connections to the site. 4294967295 means unlimited",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "4294967295",
+		ValidateDiagFunc: isUint32InBetween(0, math.MaxUint32),
+	},
+	webSiteSchema.LimitsSchema.ConnectionTimeout: {
+		Description:      "The time, in seconds or as a duration such as \"2m\", after which IIS disconnects an inactive client",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "120",
+		ValidateDiagFunc: isDurationInBetween(time.Second, 0, noMaximumDuration),
+		DiffSuppressFunc: suppressEquivalentDuration(time.Second),
+	},
+	webSiteSchema.LimitsSchema.MaxUrlSegments: {
+		Description:      "The maximum number of segments allowed in the URL path of the requests",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          32,
+		ValidateDiagFunc: isInBetweenValues(0, 16383),
+	},
+}
+
+var webSiteHstsSchema = map[string]*schema.Schema{
+	webSiteSchema.HstsSchema.Enabled: {
+		Description: "If true, the Strict-Transport-Security header is sent on the https responses",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	webSiteSchema.HstsSchema.MaxAge: {
+		Description:      "The max-age directive of the header, in seconds, during which the browsers only use https to access the site",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "0",
+		ValidateDiagFunc: isUint32InBetween(0, math.MaxUint32),
+	},
+	webSiteSchema.HstsSchema.IncludeSubDomains: {
+		Description: "If true, the includeSubDomains directive extends the policy to the sub domains of the host",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	webSiteSchema.HstsSchema.Preload: {
+		Description: "If true, the preload directive allows the browsers to ship the policy of the host",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	webSiteSchema.HstsSchema.RedirectHttpToHttps: {
+		Description: "If true, the http requests are redirected to https with a 301 status code",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+}
+
+var webSiteTracingSchema = map[string]*schema.Schema{
+	webSiteSchema.TracingSchema.Enabled: {
+		Description: "If true, the failed requests matching an iis_failed_request_tracing_rule are traced",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	webSiteSchema.TracingSchema.Directory: {
+		Description: "The directory where the trace files are written. Environment variables such as %SystemDrive% are expanded by IIS",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     `%SystemDrive%\inetpub\logs\FailedReqLogFiles`,
+	},
+	webSiteSchema.TracingSchema.MaxLogFiles: {
+		Description:      "The maximum number of trace files kept for the site, the oldest ones being deleted",
+		Type:             schema.TypeInt,
+		Optional:         true,
+		Default:          50,
+		ValidateDiagFunc: isInBetweenValues(1, 10000),
+	},
+}
+
+var webSiteLogFileSchema = map[string]*schema.Schema{
+	webSiteSchema.LogFileSchema.Enabled: {
+		Description: "If true, the requests to the site are logged",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+	},
+	webSiteSchema.LogFileSchema.LogFormat: {
+		Description:      "The format of the log files: W3C, IIS, NCSA or Custom",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "W3C",
+		ValidateDiagFunc: validateAllowedValues([]string{"W3C", "IIS", "NCSA", "Custom"}),
+	},
+	webSiteSchema.LogFileSchema.Directory: {
+		Description: "The directory where the log files are written. Environment variables such as %SystemDrive% are expanded by IIS",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     `%SystemDrive%\inetpub\logs\LogFiles`,
+	},
+	webSiteSchema.LogFileSchema.Period: {
+		Description:      "How often a new log file is created: MaxSize (when the file reaches truncate_size), Hourly, Daily, Weekly or Monthly",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "Daily",
+		ValidateDiagFunc: validateAllowedValues([]string{"MaxSize", "Hourly", "Daily", "Weekly", "Monthly"}),
+	},
+	webSiteSchema.LogFileSchema.TruncateSize: {
+		Description:      "The size (in bytes) at which a new log file is created when period is MaxSize",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "20971520",
+		ValidateDiagFunc: isUint32InBetween(1048576, math.MaxUint32),
+	},
+	webSiteSchema.LogFileSchema.LocalTimeRollover: {
+		Description: "If true, new log files are created based on the local time instead of UTC",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	},
+	webSiteSchema.LogFileSchema.LogExtFileFlags: {
+		Description: "The fields written to the W3C log files, e.g. Date, Time, ClientIP, UriStem and HttpStatus. Left as configured on the host when not set",
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+			ValidateDiagFunc: validateAllowedValues([]string{"Date", "Time", "ClientIP", "UserName", "SiteName", "ComputerName", "ServerIP", "Method", "UriStem",
+				"UriQuery", "HttpStatus", "Win32Status", "BytesSent", "BytesRecv", "TimeTaken", "ServerPort", "UserAgent", "Cookie", "Referer",
+				"ProtocolVersion", "Host", "HttpSubStatus"}),
+		},
+	},
+	webSiteSchema.LogFileSchema.LogTargetW3C: {
+		Description: "Where the W3C log entries are written: File, ETW or both (IIS 10). Left as configured on the host when not set",
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Computed:    true,
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validateAllowedValues([]string{"File", "ETW"}),
+		},
+	},
+	webSiteSchema.LogFileSchema.CustomFieldSchema.Key: {
+		Description: "The request headers, response headers and server variables appended to the W3C log entries (IIS 8.5)",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: logCustomFieldSchema,
+		},
+	},
+}
+
+var logCustomFieldSchema = map[string]*schema.Schema{
+	webSiteSchema.LogFileSchema.CustomFieldSchema.LogFieldName: {
+		Description: "The name of the field in the log files",
+		Type:        schema.TypeString,
+		Required:    true,
+	},
+	webSiteSchema.LogFileSchema.CustomFieldSchema.SourceName: {
+		Description: "The name of the header or of the server variable",
+		Type:        schema.TypeString,
+		Required:    true,
+	},
+	webSiteSchema.LogFileSchema.CustomFieldSchema.SourceType: {
+		Description:      "Where the value comes from: RequestHeader, ResponseHeader or ServerVariable",
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "RequestHeader",
+		ValidateDiagFunc: validateAllowedValues([]string{"RequestHeader", "ResponseHeader", "ServerVariable"}),
+	},
 }
 
 func resourceWebsiteCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_site", "create", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
 
 	if d.HasChange(webSiteSchema.ApplicationPoolName) {
 		appPoolName := d.Get(webSiteSchema.ApplicationPoolName).(string)
@@ -119,12 +441,22 @@ func resourceWebsiteCreate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	d.SetId(webSite.Id)
-	d.Set(webSiteSchema.State, webSite.State)
+	// The password only lives in the configuration, as it is write-only
+	if err = d.Set(webSiteSchema.Password, ""); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = applyWebSiteState(ctx, client, webSite.Name, d.Get(webSiteSchema.State).(string), d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return nil
 }
 
 func resourceWebsiteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_site", "read", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
+
 	name := d.Get(webSiteSchema.Name).(string)
 	webSite, err := client.GetWebSite(name)
 	if err != nil {
@@ -141,7 +473,8 @@ func resourceWebsiteRead(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 func resourceWebsiteUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_site", "update", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
 
 	if d.HasChange(webSiteSchema.ApplicationPoolName) {
 		appPoolName := d.Get(webSiteSchema.ApplicationPoolName).(string)
@@ -157,24 +490,49 @@ func resourceWebsiteUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	// The id of the resource follows the one of the site, planned as unknown when site_id changes
+	if d.HasChange(webSiteSchema.SiteId) && webSite.Id != "" {
+		d.SetId(webSite.Id)
+	}
+
+	if d.HasChange(webSiteSchema.State) {
+		if err = applyWebSiteState(ctx, client, webSite.Name, webSite.State, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// A parked site stays stopped, the new content being served once it is started
+	if d.HasChange(webSiteSchema.RecycleTriggers) && webSite.State == "Started" {
+		if err = client.RestartWebSite(webSite.Name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return nil
 }
 
 func resourceWebsiteDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*agent.Client)
+	client, span := startSpan(ctx, m, "iis_web_site", "delete", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
 
 	name := d.Get(webSiteSchema.Name).(string)
+	if d.Get(webSiteSchema.DeletionProtection).(bool) {
+		return diag.Errorf("web site '%s' has deletion_protection enabled, set it to false and apply before deleting it", name)
+	}
+
 	err := client.DeleteWebSite(name)
 	if err != nil {
 		d.SetId("")
 		return diag.FromErr(err)
 	}
 
-	return nil
+	return destroyPhysicalPath(client, d, webSiteSchema.PhysicalPath)
 }
 
 func importWebSiteState(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	client := meta.(*agent.Client)
+	client, span := startSpan(ctx, meta, "iis_web_site", "import", d.Id())
+	defer span.End()
+
 	webSiteName := d.Id()
 	webSite, err := client.GetWebSite(webSiteName)
 	if err != nil {
@@ -190,71 +548,405 @@ func importWebSiteState(ctx context.Context, d *schema.ResourceData, meta interf
 }
 
 func mapWebSiteToResourceData(webSite agent.WebSite, d *schema.ResourceData) error {
-	var err error
 	d.SetId(webSite.Id)
-	if err = d.Set(webSiteSchema.Name, webSite.Name); err != nil {
-		return err
+	for key, value := range flattenWebSite(webSite) {
+		if err := d.Set(key, value); err != nil {
+			return err
+		}
 	}
-	if err = d.Set(webSiteSchema.ApplicationPoolName, webSite.ApplicationPoolName); err != nil {
-		return err
+
+	// Also clears the passwords kept in the states written by the previous versions of the provider
+	return d.Set(webSiteSchema.Password, "")
+}
+
+// flattenWebSite maps the web site into the attributes shared by the resource and the data sources
+func flattenWebSite(webSite agent.WebSite) map[string]interface{} {
+	var bindings []interface{}
+	for _, binding := range webSite.Bindings {
+		var flags []interface{}
+		for _, flag := range binding.SslFlags {
+			flags = append(flags, flag)
+		}
+
+		ip, port, hostHeader := binding.Ip, binding.Port, binding.HostHeader
+		if !agent.IsIpProtocol(binding.Protocol) {
+			// The defaults keep the plan quiet, the WAS protocols ignoring those attributes
+			ip = webSiteBindingsSchema[webSiteSchema.BindingSchema.Ip].Default.(string)
+			port = webSiteBindingsSchema[webSiteSchema.BindingSchema.Port].Default.(int)
+			hostHeader = webSiteBindingsSchema[webSiteSchema.BindingSchema.HostHeader].Default.(string)
+		}
+
+		b := map[string]interface{}{
+			webSiteSchema.BindingSchema.Ip:                    ip,
+			webSiteSchema.BindingSchema.Port:                  port,
+			webSiteSchema.BindingSchema.Protocol:              binding.Protocol,
+			webSiteSchema.BindingSchema.HostHeader:            hostHeader,
+			webSiteSchema.BindingSchema.BindingInformation:    binding.Information,
+			webSiteSchema.BindingSchema.CertificateThumbprint: binding.CertificateThumbprint,
+			webSiteSchema.BindingSchema.CertificateStoreName:  binding.CertificateStoreName,
+			webSiteSchema.BindingSchema.SslFlags:              flags,
+		}
+		bindings = append(bindings, b)
 	}
-	if err = d.Set(webSiteSchema.State, webSite.State); err != nil {
-		return err
+
+	var enabledProtocols []interface{}
+	for _, protocol := range webSite.EnabledProtocols {
+		enabledProtocols = append(enabledProtocols, protocol)
 	}
-	if err = d.Set(webSiteSchema.PhysicalPath, webSite.PhysicalPath); err != nil {
-		return err
+
+	return map[string]interface{}{
+		webSiteSchema.SiteId:              webSite.Id,
+		webSiteSchema.Name:                webSite.Name,
+		webSiteSchema.ApplicationPoolName: webSite.ApplicationPoolName,
+		webSiteSchema.State:               webSite.State,
+		webSiteSchema.AutoStart:           webSite.AutoStart,
+		webSiteSchema.PhysicalPath:        webSite.PhysicalPath,
+		webSiteSchema.Username:            webSite.Username,
+		webSiteSchema.LogonMethod:         webSite.LogonMethod,
+		webSiteSchema.EnabledProtocols:    enabledProtocols,
+		webSiteSchema.BindingSchema.Key:   bindings,
+		webSiteSchema.LimitsSchema.Key:    flattenLimits(*webSite.Limits),
+		webSiteSchema.LogFileSchema.Key:   flattenLogFile(*webSite.LogFile),
+		webSiteSchema.HstsSchema.Key: []interface{}{
+			map[string]interface{}{
+				webSiteSchema.HstsSchema.Enabled:             webSite.Hsts.Enabled,
+				webSiteSchema.HstsSchema.MaxAge:              strconv.FormatInt(webSite.Hsts.MaxAge, 10),
+				webSiteSchema.HstsSchema.IncludeSubDomains:   webSite.Hsts.IncludeSubDomains,
+				webSiteSchema.HstsSchema.Preload:             webSite.Hsts.Preload,
+				webSiteSchema.HstsSchema.RedirectHttpToHttps: webSite.Hsts.RedirectHttpToHttps,
+			},
+		},
+		webSiteSchema.TracingSchema.Key: flattenTraceFailedRequestsLogging(*webSite.TraceFailedRequestsLogging),
 	}
-	if err = d.Set(webSiteSchema.Username, webSite.Username); err != nil {
-		return err
+}
+
+func mapToWebSite(d *schema.ResourceData) agent.WebSite {
+	bindings := expandBindings(d.Get(webSiteSchema.BindingSchema.Key).(*schema.Set))
+
+	var limits *agent.Limits
+	if isBlockConfigured(d, webSiteSchema.LimitsSchema.Key) {
+		value := mapToLimits(getBlockOrDefaults(d, webSiteSchema.LimitsSchema.Key, webSiteLimitsSchema))
+		limits = &value
 	}
-	if err = d.Set(webSiteSchema.Password, webSite.Password); err != nil {
-		return err
+
+	// Writing the log file or the tracing settings of a site which does not configure them would stop it from inheriting the siteDefaults
+	var logFile *agent.LogFile
+	if isBlockConfigured(d, webSiteSchema.LogFileSchema.Key) {
+		value := mapToLogFile(getBlockOrDefaults(d, webSiteSchema.LogFileSchema.Key, webSiteLogFileSchema))
+		logFile = &value
 	}
 
-	var bindings []map[string]interface{}
-	for _, binding := range webSite.Bindings {
-		b := map[string]interface{}{
-			webSiteSchema.BindingSchema.Ip:         binding.Ip,
-			webSiteSchema.BindingSchema.Port:       binding.Port,
-			webSiteSchema.BindingSchema.Protocol:   binding.Protocol,
-			webSiteSchema.BindingSchema.HostHeader: binding.HostHeader,
+	// The hsts element does not exist before IIS 10 version 1709, so it is only written when configured
+	var hsts *agent.Hsts
+	if isBlockConfigured(d, webSiteSchema.HstsSchema.Key) {
+		hstsResource := getBlockOrDefaults(d, webSiteSchema.HstsSchema.Key, webSiteHstsSchema)
+		hsts = &agent.Hsts{
+			Enabled:             hstsResource[webSiteSchema.HstsSchema.Enabled].(bool),
+			MaxAge:              getInt64(hstsResource[webSiteSchema.HstsSchema.MaxAge]),
+			IncludeSubDomains:   hstsResource[webSiteSchema.HstsSchema.IncludeSubDomains].(bool),
+			Preload:             hstsResource[webSiteSchema.HstsSchema.Preload].(bool),
+			RedirectHttpToHttps: hstsResource[webSiteSchema.HstsSchema.RedirectHttpToHttps].(bool),
 		}
-		bindings = append(bindings, b)
 	}
 
-	err = d.Set(webSiteSchema.BindingSchema.Key, bindings)
-	return err
+	var tracing *agent.TraceFailedRequestsLogging
+	if isBlockConfigured(d, webSiteSchema.TracingSchema.Key) {
+		value := mapToTraceFailedRequestsLogging(getBlockOrDefaults(d, webSiteSchema.TracingSchema.Key, webSiteTracingSchema))
+		tracing = &value
+	}
+
+	enabledProtocols := []string{}
+	for _, protocol := range d.Get(webSiteSchema.EnabledProtocols).(*schema.Set).List() {
+		enabledProtocols = append(enabledProtocols, protocol.(string))
+	}
+
+	// The password is not in the state to compare it with, so it is written on creation and when its version or the user changes
+	var password *string
+	if d.Id() == "" || d.HasChanges(webSiteSchema.Username, webSiteSchema.PasswordVersion) {
+		value := getConfiguredString(d, webSiteSchema.Password)
+		password = &value
+	}
+
+	// Changing the id restarts the site, so it is only written on creation or when site_id changes
+	siteId := ""
+	if value := getInt64(d.Get(webSiteSchema.SiteId)); value > 0 && (d.Id() == "" || d.HasChange(webSiteSchema.SiteId)) {
+		siteId = strconv.FormatInt(value, 10)
+	}
+
+	return agent.WebSite{
+		Id:                         siteId,
+		Name:                       d.Get(webSiteSchema.Name).(string),
+		State:                      d.Get(webSiteSchema.State).(string),
+		AutoStart:                  d.Get(webSiteSchema.AutoStart).(bool),
+		EnabledProtocols:           enabledProtocols,
+		Limits:                     limits,
+		LogFile:                    logFile,
+		TraceFailedRequestsLogging: tracing,
+		Hsts:                       hsts,
+		PhysicalPath:               d.Get(webSiteSchema.PhysicalPath).(string),
+		Username:                   d.Get(webSiteSchema.Username).(string),
+		Password:                   password,
+		LogonMethod:                d.Get(webSiteSchema.LogonMethod).(string),
+		ApplicationPoolName:        d.Get(webSiteSchema.ApplicationPoolName).(string),
+		Bindings:                   bindings,
+		PhysicalPathProvisioning:   expandPhysicalPathProvisioning(d, webSiteSchema.PhysicalPath),
+	}
 }
 
-func mapToWebSite(d *schema.ResourceData) agent.WebSite {
-	bindings := []agent.Binding{}
+// mapToLimits maps the limits block of a site or of the siteDefaults
+func mapToLimits(limitsResource map[string]interface{}) agent.Limits {
+	return agent.Limits{
+		MaxBandwidth:      getInt64(limitsResource[webSiteSchema.LimitsSchema.MaxBandwidth]),
+		MaxConnections:    getInt64(limitsResource[webSiteSchema.LimitsSchema.MaxConnections]),
+		ConnectionTimeout: getDuration(limitsResource[webSiteSchema.LimitsSchema.ConnectionTimeout], time.Second),
+		MaxUrlSegments:    limitsResource[webSiteSchema.LimitsSchema.MaxUrlSegments].(int),
+	}
+}
 
-	bindingsList := d.Get(webSiteSchema.BindingSchema.Key).(*schema.Set).List()
+func flattenLimits(limits agent.Limits) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			webSiteSchema.LimitsSchema.MaxBandwidth:      strconv.FormatInt(limits.MaxBandwidth, 10),
+			webSiteSchema.LimitsSchema.MaxConnections:    strconv.FormatInt(limits.MaxConnections, 10),
+			webSiteSchema.LimitsSchema.ConnectionTimeout: formatDuration(limits.ConnectionTimeout, time.Second),
+			webSiteSchema.LimitsSchema.MaxUrlSegments:    limits.MaxUrlSegments,
+		},
+	}
+}
+
+// mapToTraceFailedRequestsLogging maps the trace_failed_requests_logging block of a site or of the siteDefaults
+func mapToTraceFailedRequestsLogging(tracingResource map[string]interface{}) agent.TraceFailedRequestsLogging {
+	return agent.TraceFailedRequestsLogging{
+		Enabled:     tracingResource[webSiteSchema.TracingSchema.Enabled].(bool),
+		Directory:   tracingResource[webSiteSchema.TracingSchema.Directory].(string),
+		MaxLogFiles: tracingResource[webSiteSchema.TracingSchema.MaxLogFiles].(int),
+	}
+}
+
+func flattenTraceFailedRequestsLogging(tracing agent.TraceFailedRequestsLogging) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			webSiteSchema.TracingSchema.Enabled:     tracing.Enabled,
+			webSiteSchema.TracingSchema.Directory:   tracing.Directory,
+			webSiteSchema.TracingSchema.MaxLogFiles: tracing.MaxLogFiles,
+		},
+	}
+}
+
+// expandBindings maps the binding set, the site getting the default binding when none is configured
+func expandBindings(bindingSet *schema.Set) []agent.Binding {
+	bindings := []agent.Binding{}
+	bindingsList := bindingSet.List()
 	if len(bindingsList) > 0 {
 		for _, binding := range bindingsList {
 			bindingResource := binding.(map[string]interface{})
+			flags := []string{}
+			for _, flag := range bindingResource[webSiteSchema.BindingSchema.SslFlags].(*schema.Set).List() {
+				flags = append(flags, flag.(string))
+			}
+
 			bindings = append(bindings, agent.Binding{
-				Ip:         bindingResource[webSiteSchema.BindingSchema.Ip].(string),
-				Port:       bindingResource[webSiteSchema.BindingSchema.Port].(int),
-				Protocol:   bindingResource[webSiteSchema.BindingSchema.Protocol].(string),
-				HostHeader: bindingResource[webSiteSchema.BindingSchema.HostHeader].(string),
+				Ip:                    bindingResource[webSiteSchema.BindingSchema.Ip].(string),
+				Port:                  bindingResource[webSiteSchema.BindingSchema.Port].(int),
+				Protocol:              bindingResource[webSiteSchema.BindingSchema.Protocol].(string),
+				HostHeader:            bindingResource[webSiteSchema.BindingSchema.HostHeader].(string),
+				Information:           bindingResource[webSiteSchema.BindingSchema.BindingInformation].(string),
+				CertificateThumbprint: bindingResource[webSiteSchema.BindingSchema.CertificateThumbprint].(string),
+				CertificateStoreName:  bindingResource[webSiteSchema.BindingSchema.CertificateStoreName].(string),
+				SslFlags:              flags,
 			})
 		}
 	} else {
 		bindings = append(bindings, agent.Binding{
-			Ip:         webSiteBindingsSchema[webSiteSchema.BindingSchema.Ip].Default.(string),
-			Port:       webSiteBindingsSchema[webSiteSchema.BindingSchema.Port].Default.(int),
-			Protocol:   webSiteBindingsSchema[webSiteSchema.BindingSchema.Protocol].Default.(string),
-			HostHeader: webSiteBindingsSchema[webSiteSchema.BindingSchema.HostHeader].Default.(string),
+			Ip:                   webSiteBindingsSchema[webSiteSchema.BindingSchema.Ip].Default.(string),
+			Port:                 webSiteBindingsSchema[webSiteSchema.BindingSchema.Port].Default.(int),
+			Protocol:             webSiteBindingsSchema[webSiteSchema.BindingSchema.Protocol].Default.(string),
+			HostHeader:           webSiteBindingsSchema[webSiteSchema.BindingSchema.HostHeader].Default.(string),
+			CertificateStoreName: webSiteBindingsSchema[webSiteSchema.BindingSchema.CertificateStoreName].Default.(string),
 		})
 	}
 
-	return agent.WebSite{
-		Name:                d.Get(webSiteSchema.Name).(string),
-		PhysicalPath:        d.Get(webSiteSchema.PhysicalPath).(string),
-		Username:            d.Get(webSiteSchema.Username).(string),
-		Password:            d.Get(webSiteSchema.Password).(string),
-		ApplicationPoolName: d.Get(webSiteSchema.ApplicationPoolName).(string),
-		Bindings:            bindings,
+	return bindings
+}
+
+// applyWebSiteState starts or stops the site and waits until it settles in the desired state
+func applyWebSiteState(ctx context.Context, client *agent.Client, name string, desiredState string, timeout time.Duration) error {
+	currentState, err := client.GetWebSiteState(name)
+	if err != nil {
+		return err
+	}
+
+	if currentState == desiredState {
+		return nil
+	}
+
+	pending := []string{"Starting"}
+	if desiredState == "Started" {
+		err = client.StartWebSite(name)
+	} else {
+		pending = []string{"Stopping"}
+		err = client.StopWebSite(name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to change the state of web site '%s' to '%s': %w", name, desiredState, err)
+	}
+
+	stateChange := &retry.StateChangeConf{
+		Pending:    pending,
+		Target:     []string{desiredState},
+		Timeout:    timeout,
+		MinTimeout: 1 * time.Second,
+		Refresh: func() (interface{}, string, error) {
+			state, err := client.GetWebSiteState(name)
+			return state, state, err
+		},
+	}
+
+	if _, err = stateChange.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("web site '%s' did not reach the '%s' state: %w", name, desiredState, err)
+	}
+
+	return nil
+}
+
+func customizeWebSiteDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := validateBindingsDiff(d); err != nil {
+		return err
+	}
+
+	if err := validateSiteIdDiff(ctx, d, m); err != nil {
+		return err
+	}
+
+	if err := validatePhysicalPathReplacementDiff(d, webSiteSchema.PhysicalPath, webSiteSchema.Name); err != nil {
+		return err
+	}
+
+	if err := validateBindingConflictsDiff(ctx, d, m); err != nil {
+		return err
 	}
+
+	return validateHstsDiff(ctx, d, m)
+}
+
+// validateSiteIdDiff fails the plan when the requested site id already belongs to another site of the host
+func validateSiteIdDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange(webSiteSchema.SiteId) || !d.NewValueKnown(webSiteSchema.SiteId) {
+		return nil
+	}
+
+	siteId := getInt64(d.Get(webSiteSchema.SiteId))
+	if siteId == 0 {
+		return nil
+	}
+
+	// The id of the resource is the one of the site, which changes along with it
+	if d.Id() != "" {
+		if err := d.SetNewComputed(webSiteSchema.Id); err != nil {
+			return err
+		}
+	}
+
+	client, span := startSpan(ctx, m, "iis_web_site", "plan", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
+
+	webSites, err := client.ListWebSites()
+	if err != nil {
+		return err
+	}
+
+	name := d.Get(webSiteSchema.Name).(string)
+	for _, webSite := range webSites {
+		if webSite.Id == strconv.FormatInt(siteId, 10) && webSite.Name != name {
+			return fmt.Errorf("%q %d is already used by web site '%s'", webSiteSchema.SiteId, siteId, webSite.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateHstsDiff fails the plan when the hsts block is configured on a host lacking the native HSTS support
+func validateHstsDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !isBlockConfigured(d, webSiteSchema.HstsSchema.Key) {
+		return nil
+	}
+
+	// The host is only asked for its version when the block changes, to keep the plans of unchanged sites offline
+	if !d.HasChange(webSiteSchema.HstsSchema.Key) {
+		return nil
+	}
+
+	client, span := startSpan(ctx, m, "iis_web_site", "plan", d.Get(webSiteSchema.Name).(string))
+	defer span.End()
+
+	version, err := client.GetServerVersion()
+	if err != nil {
+		return err
+	}
+
+	if !version.SupportsHsts() {
+		return fmt.Errorf("%q requires IIS 10 on Windows Server version 1709 (build 16299) or later, but the host runs %s", webSiteSchema.HstsSchema.Key, version)
+	}
+
+	return nil
+}
+
+// validateBindingsDiff checks that the certificate and the SSL flags of the bindings make sense for their protocol
+func validateBindingsDiff(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown(webSiteSchema.BindingSchema.Key) {
+		return nil
+	}
+
+	for _, value := range d.Get(webSiteSchema.BindingSchema.Key).(*schema.Set).List() {
+		binding := value.(map[string]interface{})
+		protocol := binding[webSiteSchema.BindingSchema.Protocol].(string)
+		hostHeader := binding[webSiteSchema.BindingSchema.HostHeader].(string)
+		thumbprint := binding[webSiteSchema.BindingSchema.CertificateThumbprint].(string)
+		information := binding[webSiteSchema.BindingSchema.BindingInformation].(string)
+		if !agent.IsIpProtocol(protocol) {
+			if information == "" {
+				return fmt.Errorf("binding %s: %q is required for the %s protocol", protocol, webSiteSchema.BindingSchema.BindingInformation, protocol)
+			}
+		} else {
+			if information != "" {
+				return fmt.Errorf("binding %s: %q is only used by the WAS protocols, use ip, port and host_header instead", protocol, webSiteSchema.BindingSchema.BindingInformation)
+			}
+
+			information = agent.FormatBindingInformation(binding[webSiteSchema.BindingSchema.Ip].(string), binding[webSiteSchema.BindingSchema.Port].(int), hostHeader)
+		}
+
+		name := fmt.Sprintf("%s://%s", protocol, information)
+		flags := []string{}
+		for _, flag := range binding[webSiteSchema.BindingSchema.SslFlags].(*schema.Set).List() {
+			flags = append(flags, flag.(string))
+		}
+
+		if protocol != "https" {
+			if thumbprint != "" || len(flags) > 0 {
+				return fmt.Errorf("binding %s: %q and %q can only be set on https bindings", name, webSiteSchema.BindingSchema.CertificateThumbprint, webSiteSchema.BindingSchema.SslFlags)
+			}
+
+			continue
+		}
+
+		isCentralCertStore := slices.Contains(flags, "CentralCertStore")
+		if isCentralCertStore && thumbprint != "" {
+			return fmt.Errorf("binding %s: %q must not be set when the certificate comes from the Central Certificate Store", name, webSiteSchema.BindingSchema.CertificateThumbprint)
+		}
+
+		if !isCentralCertStore && thumbprint == "" {
+			return fmt.Errorf("binding %s: %q is required, unless %q contains 'CentralCertStore'", name, webSiteSchema.BindingSchema.CertificateThumbprint, webSiteSchema.BindingSchema.SslFlags)
+		}
+
+		if isCentralCertStore && !slices.Contains(flags, "Sni") {
+			return fmt.Errorf("binding %s: 'CentralCertStore' requires 'Sni' in %q", name, webSiteSchema.BindingSchema.SslFlags)
+		}
+
+		if slices.Contains(flags, "Sni") && hostHeader == "" {
+			return fmt.Errorf("binding %s: 'Sni' requires %q, the certificate being selected by host name", name, webSiteSchema.BindingSchema.HostHeader)
+		}
+	}
+
+	return nil
 }
@@ -1,97 +1,325 @@
 package iis
 
 type applicationPoolSchemaKeys struct {
-	Id                 string
-	Name               string
-	State              string
-	AutoStart          string
-	StartMode          string
-	PipelineMode       string
-	RuntimeVersion     string
-	Enable32Bit        string
-	QueueLength        string
-	ProcessModelSchema applicationPoolProcessModelSchemaKeys
+	Id                            string
+	Name                          string
+	State                         string
+	AutoStart                     string
+	StartMode                     string
+	PipelineMode                  string
+	RuntimeVersion                string
+	Enable32Bit                   string
+	QueueLength                   string
+	EnvironmentVariables          string
+	SensitiveEnvironmentVariables string
+	RecycleTriggers               string
+	RecycleWaitForWorkerProcess   string
+	ForceDelete                   string
+	ReassignTo                    string
+	DeletionProtection            string
+	ProcessModelSchema            applicationPoolProcessModelSchemaKeys
+	CPUSchema                     applicationPoolCPUSchemaKeys
+	RecyclingSchema               applicationPoolRecyclingSchemaKeys
+	FailureSchema                 applicationPoolFailureSchemaKeys
 }
 
 type applicationPoolProcessModelSchemaKeys struct {
-	Key                 string
-	IdentityType        string
-	Username            string
-	Password            string
-	LoadUserProfile     string
-	IdleTimeout         string
-	IdleTimeoutAction   string
-	MaxProcesses        string
-	PingingEnabled      string
-	PingingInterval     string
-	PingingResponseTime string
-	StartupTimeLimit    string
-	ShutdownTimeLimit   string
+	Key                           string
+	IdentityType                  string
+	Username                      string
+	Password                      string
+	LoadUserProfile               string
+	IdleTimeout                   string
+	IdleTimeoutAction             string
+	MaxProcesses                  string
+	PingingEnabled                string
+	PingingInterval               string
+	PingingResponseTime           string
+	StartupTimeLimit              string
+	ShutdownTimeLimit             string
+	LogIdleTimeoutEvent           string
+	LogonType                     string
+	ManualGroupMembership         string
+	SetProfileEnvironment         string
+	RequestQueueDelegatorIdentity string
+}
+
+type applicationPoolCPUSchemaKeys struct {
+	Key                      string
+	Limit                    string
+	LimitInterval            string
+	Action                   string
+	ProcessorAffinityEnabled string
+	ProcessorAffinityMask32  string
+	ProcessorAffinityMask64  string
+	NumaNodeAssignment       string
+	NumaNodeAffinityMode     string
+}
+
+type applicationPoolRecyclingSchemaKeys struct {
+	Key                          string
+	RegularTimeInterval          string
+	Schedule                     string
+	RequestLimit                 string
+	VirtualMemoryLimit           string
+	PrivateMemoryLimit           string
+	DisableOverlappedRecycle     string
+	DisableRecycleOnConfigChange string
+	LogEventsSchema              applicationPoolRecyclingLogEventsSchemaKeys
+}
+
+type applicationPoolRecyclingLogEventsSchemaKeys struct {
+	Key            string
+	Time           string
+	Requests       string
+	Schedule       string
+	Memory         string
+	IsapiUnhealthy string
+	OnDemand       string
+	ConfigChange   string
+	PrivateMemory  string
+}
+
+type applicationPoolFailureSchemaKeys struct {
+	Key                           string
+	RapidFailProtectionEnabled    string
+	RapidFailProtectionInterval   string
+	RapidFailProtectionMaxCrashes string
+	ShutdownExecutable            string
+	ShutdownExecutableParameters  string
+	ServiceUnavailableResponse    string
+	OrphanWorkerProcess           string
+	OrphanActionExecutable        string
+	OrphanActionParameters        string
 }
 
 var applicationPoolSchema = applicationPoolSchemaKeys{
-	Id:             "id",
-	Name:           "name",
-	State:          "state",
-	AutoStart:      "auto_start",
-	StartMode:      "start_mode",
-	PipelineMode:   "pipeline_mode",
-	RuntimeVersion: "runtime_version",
-	Enable32Bit:    "enable_32bit",
-	QueueLength:    "queue_length",
+	Id:                            "id",
+	Name:                          "name",
+	State:                         "state",
+	AutoStart:                     "auto_start",
+	StartMode:                     "start_mode",
+	PipelineMode:                  "pipeline_mode",
+	RuntimeVersion:                "runtime_version",
+	Enable32Bit:                   "enable_32bit",
+	QueueLength:                   "queue_length",
+	EnvironmentVariables:          "environment_variables",
+	SensitiveEnvironmentVariables: "sensitive_environment_variables",
+	RecycleTriggers:               "recycle_triggers",
+	RecycleWaitForWorkerProcess:   "recycle_wait_for_worker_process",
+	ForceDelete:                   "force_delete",
+	ReassignTo:                    "reassign_to",
+	DeletionProtection:            "deletion_protection",
 	ProcessModelSchema: applicationPoolProcessModelSchemaKeys{
-		Key:                 "process_model",
-		IdentityType:        "identity_type",
-		Username:            "username",
-		Password:            "password",
-		LoadUserProfile:     "load_user_profile",
-		IdleTimeout:         "idle_timeout",
-		IdleTimeoutAction:   "idle_timeout_action",
-		MaxProcesses:        "max_processes",
-		PingingEnabled:      "pinging_enabled",
-		PingingInterval:     "pinging_interval",
-		PingingResponseTime: "pinging_response_time",
-		StartupTimeLimit:    "startup_time_limit",
-		ShutdownTimeLimit:   "shutdown_time_limit",
+		Key:                           "process_model",
+		IdentityType:                  "identity_type",
+		Username:                      "username",
+		Password:                      "password",
+		LoadUserProfile:               "load_user_profile",
+		IdleTimeout:                   "idle_timeout",
+		IdleTimeoutAction:             "idle_timeout_action",
+		MaxProcesses:                  "max_processes",
+		PingingEnabled:                "pinging_enabled",
+		PingingInterval:               "pinging_interval",
+		PingingResponseTime:           "pinging_response_time",
+		StartupTimeLimit:              "startup_time_limit",
+		ShutdownTimeLimit:             "shutdown_time_limit",
+		LogIdleTimeoutEvent:           "log_idle_timeout_event",
+		LogonType:                     "logon_type",
+		ManualGroupMembership:         "manual_group_membership",
+		SetProfileEnvironment:         "set_profile_environment",
+		RequestQueueDelegatorIdentity: "request_queue_delegator_identity",
+	},
+	CPUSchema: applicationPoolCPUSchemaKeys{
+		Key:                      "cpu",
+		Limit:                    "limit",
+		LimitInterval:            "limit_interval",
+		Action:                   "action",
+		ProcessorAffinityEnabled: "processor_affinity_enabled",
+		ProcessorAffinityMask32:  "processor_affinity_mask32",
+		ProcessorAffinityMask64:  "processor_affinity_mask64",
+		NumaNodeAssignment:       "numa_node_assignment",
+		NumaNodeAffinityMode:     "numa_node_affinity_mode",
+	},
+	RecyclingSchema: applicationPoolRecyclingSchemaKeys{
+		Key:                          "recycling",
+		RegularTimeInterval:          "regular_time_interval",
+		Schedule:                     "schedule",
+		RequestLimit:                 "request_limit",
+		VirtualMemoryLimit:           "virtual_memory_limit",
+		PrivateMemoryLimit:           "private_memory_limit",
+		DisableOverlappedRecycle:     "disable_overlapped_recycle",
+		DisableRecycleOnConfigChange: "disable_recycle_on_config_change",
+		LogEventsSchema: applicationPoolRecyclingLogEventsSchemaKeys{
+			Key:            "log_events",
+			Time:           "time",
+			Requests:       "requests",
+			Schedule:       "schedule",
+			Memory:         "memory",
+			IsapiUnhealthy: "isapi_unhealthy",
+			OnDemand:       "on_demand",
+			ConfigChange:   "config_change",
+			PrivateMemory:  "private_memory",
+		},
+	},
+	FailureSchema: applicationPoolFailureSchemaKeys{
+		Key:                           "failure",
+		RapidFailProtectionEnabled:    "rapid_fail_protection_enabled",
+		RapidFailProtectionInterval:   "rapid_fail_protection_interval",
+		RapidFailProtectionMaxCrashes: "rapid_fail_protection_max_crashes",
+		ShutdownExecutable:            "shutdown_executable",
+		ShutdownExecutableParameters:  "shutdown_executable_parameters",
+		ServiceUnavailableResponse:    "service_unavailable_response",
+		OrphanWorkerProcess:           "orphan_worker_process",
+		OrphanActionExecutable:        "orphan_action_executable",
+		OrphanActionParameters:        "orphan_action_parameters",
 	},
 }
 
 type webSiteSchemaKeys struct {
 	Id                  string
+	SiteId              string
 	Name                string
 	ApplicationPoolName string
 	State               string
+	AutoStart           string
 	PhysicalPath        string
 	Username            string
 	Password            string
+	PasswordVersion     string
+	LogonMethod         string
+	RecycleTriggers     string
+	DeletionProtection  string
+	EnabledProtocols    string
 	BindingSchema       webSiteBindingSchemaKeys
+	LimitsSchema        webSiteLimitsSchemaKeys
+	LogFileSchema       webSiteLogFileSchemaKeys
+	TracingSchema       webSiteTracingSchemaKeys
+	HstsSchema          webSiteHstsSchemaKeys
+}
+
+type webSiteHstsSchemaKeys struct {
+	Key                 string
+	Enabled             string
+	MaxAge              string
+	IncludeSubDomains   string
+	Preload             string
+	RedirectHttpToHttps string
+}
+
+type webSiteTracingSchemaKeys struct {
+	Key         string
+	Enabled     string
+	Directory   string
+	MaxLogFiles string
+}
+
+type webSiteLimitsSchemaKeys struct {
+	Key               string
+	MaxBandwidth      string
+	MaxConnections    string
+	ConnectionTimeout string
+	MaxUrlSegments    string
 }
 
 type webSiteBindingSchemaKeys struct {
-	Key        string
-	Protocol   string
-	Ip         string
-	Port       string
-	HostHeader string
+	Key                   string
+	Protocol              string
+	Ip                    string
+	Port                  string
+	HostHeader            string
+	BindingInformation    string
+	CertificateThumbprint string
+	CertificateStoreName  string
+	SslFlags              string
 }
 
 var webSiteSchema = webSiteSchemaKeys{
 	Id:                  "id",
+	SiteId:              "site_id",
 	Name:                "name",
 	ApplicationPoolName: "application_pool_name",
 	State:               "state",
+	AutoStart:           "auto_start",
 	PhysicalPath:        "physical_path",
 	Username:            "username",
 	Password:            "password",
+	PasswordVersion:     "password_version",
+	LogonMethod:         "logon_method",
+	RecycleTriggers:     "recycle_triggers",
+	DeletionProtection:  "deletion_protection",
+	EnabledProtocols:    "enabled_protocols",
 	BindingSchema: webSiteBindingSchemaKeys{
-		Key:        "binding",
-		Protocol:   "protocol",
-		Ip:         "ip",
-		Port:       "port",
-		HostHeader: "host_header",
+		Key:                   "binding",
+		Protocol:              "protocol",
+		Ip:                    "ip",
+		Port:                  "port",
+		HostHeader:            "host_header",
+		BindingInformation:    "binding_information",
+		CertificateThumbprint: "certificate_thumbprint",
+		CertificateStoreName:  "certificate_store_name",
+		SslFlags:              "ssl_flags",
+	},
+	LimitsSchema: webSiteLimitsSchemaKeys{
+		Key:               "limits",
+		MaxBandwidth:      "max_bandwidth",
+		MaxConnections:    "max_connections",
+		ConnectionTimeout: "connection_timeout",
+		MaxUrlSegments:    "max_url_segments",
+	},
+	HstsSchema: webSiteHstsSchemaKeys{
+		Key:                 "hsts",
+		Enabled:             "enabled",
+		MaxAge:              "max_age",
+		IncludeSubDomains:   "include_sub_domains",
+		Preload:             "preload",
+		RedirectHttpToHttps: "redirect_http_to_https",
+	},
+	TracingSchema: webSiteTracingSchemaKeys{
+		Key:         "trace_failed_requests_logging",
+		Enabled:     "enabled",
+		Directory:   "directory",
+		MaxLogFiles: "max_log_files",
+	},
+	LogFileSchema: webSiteLogFileSchemaKeys{
+		Key:               "log_file",
+		Enabled:           "enabled",
+		LogFormat:         "log_format",
+		Directory:         "directory",
+		Period:            "period",
+		TruncateSize:      "truncate_size",
+		LocalTimeRollover: "local_time_rollover",
+		LogExtFileFlags:   "log_ext_file_flags",
+		LogTargetW3C:      "log_target_w3c",
+		CustomFieldSchema: logCustomFieldSchemaKeys{
+			Key:          "custom_field",
+			LogFieldName: "log_field_name",
+			SourceName:   "source_name",
+			SourceType:   "source_type",
+		},
 	},
 }
 
+type webSiteLogFileSchemaKeys struct {
+	Key               string
+	Enabled           string
+	LogFormat         string
+	Directory         string
+	Period            string
+	TruncateSize      string
+	LocalTimeRollover string
+	LogExtFileFlags   string
+	LogTargetW3C      string
+	CustomFieldSchema logCustomFieldSchemaKeys
+}
+
+type logCustomFieldSchemaKeys struct {
+	Key          string
+	LogFieldName string
+	SourceName   string
+	SourceType   string
+}
+
 type webApplicationSchemaKeys struct {
 	Id                  string
 	Path                string
@@ -99,6 +327,11 @@ type webApplicationSchemaKeys struct {
 	PhysicalPath        string
 	Site                string
 	ApplicationPoolName string
+	Username            string
+	Password            string
+	PasswordVersion     string
+	LogonMethod         string
+	DeletionProtection  string
 }
 
 var webAppSchema = webApplicationSchemaKeys{
@@ -108,4 +341,190 @@ var webAppSchema = webApplicationSchemaKeys{
 	PhysicalPath:        "physical_path",
 	Site:                "web_site_name",
 	ApplicationPoolName: "application_pool_name",
+	Username:            "username",
+	Password:            "password",
+	PasswordVersion:     "password_version",
+	LogonMethod:         "logon_method",
+	DeletionProtection:  "deletion_protection",
+}
+
+type workerProcessesSchemaKeys struct {
+	Id                       string
+	ApplicationPoolName      string
+	RequestTimeElapsedFilter string
+	WorkerProcessSchema      workerProcessSchemaKeys
+}
+
+type workerProcessSchemaKeys struct {
+	Key                 string
+	ApplicationPoolName string
+	ProcessId           string
+	State               string
+	StartTime           string
+	PrivateBytes        string
+	RequestSchema       workerProcessRequestSchemaKeys
+}
+
+type workerProcessRequestSchemaKeys struct {
+	Key             string
+	Url             string
+	Verb            string
+	HostName        string
+	ClientIpAddress string
+	CurrentModule   string
+	TimeElapsed     string
+}
+
+var workerProcessesSchema = workerProcessesSchemaKeys{
+	Id:                       "id",
+	ApplicationPoolName:      "application_pool_name",
+	RequestTimeElapsedFilter: "request_time_elapsed_filter",
+	WorkerProcessSchema: workerProcessSchemaKeys{
+		Key:                 "worker_processes",
+		ApplicationPoolName: "application_pool_name",
+		ProcessId:           "process_id",
+		State:               "state",
+		StartTime:           "start_time",
+		PrivateBytes:        "private_bytes",
+		RequestSchema: workerProcessRequestSchemaKeys{
+			Key:             "requests",
+			Url:             "url",
+			Verb:            "verb",
+			HostName:        "host_name",
+			ClientIpAddress: "client_ip_address",
+			CurrentModule:   "current_module",
+			TimeElapsed:     "time_elapsed",
+		},
+	},
+}
+
+type applicationPoolsSchemaKeys struct {
+	NameRegex             string
+	State                 string
+	PipelineMode          string
+	ManagedRuntimeVersion string
+	ApplicationPools      string
+}
+
+var applicationPoolsSchema = applicationPoolsSchemaKeys{
+	NameRegex:             "name_regex",
+	State:                 "state",
+	PipelineMode:          "pipeline_mode",
+	ManagedRuntimeVersion: "runtime_version",
+	ApplicationPools:      "application_pools",
+}
+
+type webSitesSchemaKeys struct {
+	NameRegex           string
+	State               string
+	ApplicationPoolName string
+	BindingHostHeader   string
+	BindingPort         string
+	WebSites            string
+}
+
+var webSitesSchema = webSitesSchemaKeys{
+	NameRegex:           "name_regex",
+	State:               "state",
+	ApplicationPoolName: "application_pool_name",
+	BindingHostHeader:   "binding_host_header",
+	BindingPort:         "binding_port",
+	WebSites:            "web_sites",
+}
+
+type webApplicationsSchemaKeys struct {
+	NameRegex           string
+	Site                string
+	ApplicationPoolName string
+	WebApplications     string
+}
+
+var webApplicationsSchema = webApplicationsSchemaKeys{
+	NameRegex:           "name_regex",
+	Site:                "web_site_name",
+	ApplicationPoolName: "application_pool_name",
+	WebApplications:     "web_applications",
+}
+
+type siteDefaultsSchemaKeys struct {
+	AutoStart     string
+	LimitsSchema  webSiteLimitsSchemaKeys
+	LogFileSchema webSiteLogFileSchemaKeys
+	TracingSchema webSiteTracingSchemaKeys
+}
+
+var siteDefaultsSchema = siteDefaultsSchemaKeys{
+	AutoStart:     "auto_start",
+	LimitsSchema:  webSiteSchema.LimitsSchema,
+	LogFileSchema: webSiteSchema.LogFileSchema,
+	TracingSchema: webSiteSchema.TracingSchema,
+}
+
+type applicationDefaultsSchemaKeys struct {
+	ApplicationPoolName      string
+	EnabledProtocols         string
+	PreloadEnabled           string
+	ServiceAutoStartEnabled  string
+	ServiceAutoStartProvider string
+}
+
+var applicationDefaultsSchema = applicationDefaultsSchemaKeys{
+	ApplicationPoolName:      "application_pool_name",
+	EnabledProtocols:         "enabled_protocols",
+	PreloadEnabled:           "preload_enabled",
+	ServiceAutoStartEnabled:  "service_auto_start_enabled",
+	ServiceAutoStartProvider: "service_auto_start_provider",
+}
+
+type failedRequestTracingRuleSchemaKeys struct {
+	WebSiteName     string
+	Path            string
+	StatusCodes     string
+	TimeTaken       string
+	Verbosity       string
+	TraceAreaSchema traceAreaSchemaKeys
+}
+
+type traceAreaSchemaKeys struct {
+	Key       string
+	Provider  string
+	Areas     string
+	Verbosity string
+}
+
+var failedRequestTracingRuleSchema = failedRequestTracingRuleSchemaKeys{
+	WebSiteName: "web_site_name",
+	Path:        "path",
+	StatusCodes: "status_codes",
+	TimeTaken:   "time_taken",
+	Verbosity:   "verbosity",
+	TraceAreaSchema: traceAreaSchemaKeys{
+		Key:       "trace_area",
+		Provider:  "provider",
+		Areas:     "areas",
+		Verbosity: "verbosity",
+	},
+}
+
+// physicalPathSchemaKeys are shared by the resources which own the directory of their physical path
+type physicalPathSchemaKeys struct {
+	CreatePhysicalPath string
+	OnDestroy          string
+	PermissionSchema   physicalPathPermissionSchemaKeys
+}
+
+type physicalPathPermissionSchemaKeys struct {
+	Key      string
+	Identity string
+	Rights   string
+}
+
+var physicalPathSchema = physicalPathSchemaKeys{
+	CreatePhysicalPath: "create_physical_path",
+	OnDestroy:          "on_destroy",
+	PermissionSchema: physicalPathPermissionSchemaKeys{
+		Key:      "physical_path_permission",
+		Identity: "identity",
+		Rights:   "rights",
+	},
 }
@@ -0,0 +1,72 @@
+package iis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracingServiceName = "terraform-provider-iis"
+
+var tracer = otel.Tracer("github.com/rickedb/terraform-provider-iis/iis")
+
+// ConfigureTracing installs an OTLP/HTTP trace exporter when tracing is requested through the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables.
+// The returned function flushes and stops the exporter, and must be called before the provider exits
+func ConfigureTracing(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !isTracingEnabled() {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", tracingServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create the tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func isTracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+
+	return len(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")) > 0 || len(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")) > 0
+}
+
+// startSpan starts the span of a resource or data source operation and returns a client bound to it,
+// so that every command executed during the operation is traced as a child span
+func startSpan(ctx context.Context, m interface{}, resourceType string, operation string, name string) (*agent.Client, trace.Span) {
+	client := m.(*agent.Client)
+	ctx, span := tracer.Start(ctx, fmt.Sprintf("%s.%s", resourceType, operation), trace.WithAttributes(
+		attribute.String("iis.host", client.Host()),
+		attribute.String("iis.resource_type", resourceType),
+		attribute.String("iis.resource_name", name),
+	))
+
+	return client.WithContext(ctx), span
+}
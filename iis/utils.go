@@ -1,7 +1,9 @@
 package iis
 
 import (
+	"net"
 	"regexp"
+	"strconv"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -43,6 +45,25 @@ func isInBetweenValues(minValue int, maxValue int) schema.SchemaValidateDiagFunc
 	}
 }
 
+// isUint32InBetween validates the numbers kept as strings because they may not fit the int of 32 bits platforms,
+// like most of the unsigned 32 bits attributes of IIS
+func isUint32InBetween(minValue int64, maxValue int64) schema.SchemaValidateDiagFunc {
+	return func(val interface{}, path cty.Path) diag.Diagnostics {
+		v, err := strconv.ParseInt(val.(string), 10, 64)
+		if err == nil && v >= minValue && v <= maxValue {
+			return nil
+		}
+
+		return diag.Errorf("%q must be a number between %v and %v", path, minValue, maxValue)
+	}
+}
+
+// getInt64 parses the value of an attribute validated by isUint32InBetween, an empty value being 0
+func getInt64(value interface{}) int64 {
+	v, _ := strconv.ParseInt(value.(string), 10, 64)
+	return v
+}
+
 func validateAllowedIntValues(allowedValues []int) schema.SchemaValidateDiagFunc {
 	return func(val interface{}, path cty.Path) diag.Diagnostics {
 		v := val.(int)
@@ -75,3 +96,96 @@ func isValidPath(onlyBackslashOnly bool) schema.SchemaValidateDiagFunc {
 
 	return isValid(regexp.MustCompile(pattern))
 }
+
+// getBlockOrDefaults returns the attributes of a single nested block, or its schema defaults when the block is not configured
+func getBlockOrDefaults(d *schema.ResourceData, key string, blockSchema map[string]*schema.Schema) map[string]interface{} {
+	return blockOrDefaults(d.Get(key), blockSchema)
+}
+
+// blockOrDefaults does the same as getBlockOrDefaults for a block nested in another block
+func blockOrDefaults(value interface{}, blockSchema map[string]*schema.Schema) map[string]interface{} {
+	blockList, _ := value.([]interface{})
+	if len(blockList) > 0 && blockList[0] != nil {
+		return blockList[0].(map[string]interface{})
+	}
+
+	defaults := map[string]interface{}{}
+	for attribute, attributeSchema := range blockSchema {
+		if attributeSchema.Default != nil {
+			defaults[attribute] = attributeSchema.Default
+		} else if attributeSchema.Type == schema.TypeList {
+			defaults[attribute] = []interface{}{}
+		}
+	}
+
+	return defaults
+}
+
+// isBlockConfigured reports whether a computed nested block is set in the configuration, rather than only read from the host
+func isBlockConfigured(d interface{ GetRawConfig() cty.Value }, key string) bool {
+	block := d.GetRawConfig().GetAttr(key)
+	return block.IsKnown() && !block.IsNull() && block.LengthInt() > 0
+}
+
+// getConfiguredString returns the string of the configuration, for the write-only attributes whose value is not kept in the state
+func getConfiguredString(d interface{ GetRawConfig() cty.Value }, key string) string {
+	value := d.GetRawConfig().GetAttr(key)
+	if !value.IsKnown() || value.IsNull() {
+		return ""
+	}
+
+	return value.AsString()
+}
+
+// suppressWriteOnlyDiff ignores the changes of a write-only attribute once the resource exists, its state being always empty
+func suppressWriteOnlyDiff(k, old, new string, d *schema.ResourceData) bool {
+	return d.Id() != ""
+}
+
+// computedSchema copies the schema of a single item data source so that it can describe the elements of a list
+func computedSchema(source map[string]*schema.Schema) map[string]*schema.Schema {
+	result := map[string]*schema.Schema{}
+	for key, attribute := range source {
+		result[key] = &schema.Schema{
+			Description: attribute.Description,
+			Type:        attribute.Type,
+			Elem:        attribute.Elem,
+			Sensitive:   attribute.Sensitive,
+			Computed:    true,
+		}
+	}
+
+	return result
+}
+
+// compileNameRegex compiles the optional name filter of the list data sources
+func compileNameRegex(d *schema.ResourceData, key string) (*regexp.Regexp, error) {
+	pattern, ok := d.GetOk(key)
+	if !ok {
+		return nil, nil
+	}
+
+	return regexp.Compile(pattern.(string))
+}
+
+func validateRegex() schema.SchemaValidateDiagFunc {
+	return func(val interface{}, path cty.Path) diag.Diagnostics {
+		if _, err := regexp.Compile(val.(string)); err != nil {
+			return diag.Errorf("%q is not a valid regular expression: %s", path, err)
+		}
+
+		return nil
+	}
+}
+
+// isValidBindingIp accepts *, an IPv4 address or an IPv6 address without brackets
+func isValidBindingIp() schema.SchemaValidateDiagFunc {
+	return func(val interface{}, path cty.Path) diag.Diagnostics {
+		v := val.(string)
+		if v == "*" || net.ParseIP(v) != nil {
+			return nil
+		}
+
+		return diag.Errorf("%q must be * or an IP address, IPv6 addresses being written without brackets, got: %q", path, v)
+	}
+}
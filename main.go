@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"log"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	iis "github.com/rickedb/terraform-provider-iis/iis"
 )
 
 func main() {
+	ctx := context.Background()
+	shutdownTracing, err := iis.ConfigureTracing(ctx)
+	if err != nil {
+		log.Printf("[WARN] tracing is disabled: %s", err)
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: iis.Provider,
 	})
+
+	if err = shutdownTracing(ctx); err != nil {
+		log.Printf("[WARN] failed to flush traces: %s", err)
+	}
 }
@@ -2,6 +2,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
@@ -70,3 +71,143 @@ func stringPtr(s string) *string {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestUpdateAppPoolCPU(t *testing.T) {
+	client := agent.Client{}
+
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		PipelineMode: "Integrated",
+		CPU: agent.CPU{
+			Limit:                    50000,
+			LimitInterval:            agent.TimeSpan(5 * time.Minute),
+			Action:                   "ThrottleUnderLoad",
+			ProcessorAffinityEnabled: true,
+			ProcessorAffinityMask32:  3,
+			ProcessorAffinityMask64:  0,
+			NumaNodeAssignment:       "WindowsScheduling",
+			NumaNodeAffinityMode:     "Hard",
+		},
+	}
+	client.UpdateAppPool(pool)
+}
+
+func TestUpdateAppPoolRecycling(t *testing.T) {
+	client := agent.Client{}
+
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		PipelineMode: "Integrated",
+		Recycling: agent.Recycling{
+			DisableOverlappedRecycle: true,
+			LogEvents: agent.LogEvents{
+				Time:     true,
+				Schedule: true,
+			},
+			PeriodicRestart: agent.PeriodicRestart{
+				TimeInterval: agent.TimeSpan(36 * time.Hour),
+				Schedule:     []string{"03:00", "15:00"},
+				RequestLimit: 100000,
+			},
+		},
+	}
+	client.UpdateAppPool(pool)
+}
+
+func TestUpdateAppPoolFailure(t *testing.T) {
+	client := agent.Client{}
+
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		PipelineMode: "Integrated",
+		Failure: agent.Failure{
+			RapidFailProtectionEnabled:    true,
+			RapidFailProtectionInterval:   agent.TimeSpan(5 * time.Minute),
+			RapidFailProtectionMaxCrashes: 3,
+			AutoShutdownExe:               `C:\scripts\remove-from-lb.exe`,
+			LoadBalancerCapabilities:      "TcpLevel",
+		},
+	}
+	client.UpdateAppPool(pool)
+}
+
+func TestUpdateAppPoolManagedServiceAccount(t *testing.T) {
+	client := agent.Client{}
+
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		PipelineMode: "Integrated",
+		ProcessModel: agent.ProcessModel{
+			IdentityType: "SpecificUser",
+			Username:     `CONTOSO\svc-iis$`,
+			Password:     stringPtr(""),
+		},
+	}
+	client.UpdateAppPool(pool)
+}
+
+func TestStopAndStartAppPool(t *testing.T) {
+	client := agent.Client{}
+
+	client.StopAppPool("IntegrationTestPool")
+	client.GetAppPoolState("IntegrationTestPool")
+	client.StartAppPool("IntegrationTestPool")
+}
+
+func TestUpdateAppPoolEnvironmentVariables(t *testing.T) {
+	client := agent.Client{}
+
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		PipelineMode: "Integrated",
+		EnvironmentVariables: map[string]string{
+			"ASPNETCORE_ENVIRONMENT": "Staging",
+			"FEATURE_FLAGS":          "new-checkout;dark-mode",
+		},
+	}
+	client.UpdateAppPool(pool)
+}
+
+func TestRecycleAppPool(t *testing.T) {
+	client := agent.Client{}
+
+	client.GetAppPoolWorkerProcessIds("IntegrationTestPool")
+	client.RecycleAppPool("IntegrationTestPool")
+}
+
+func TestListAppPools(t *testing.T) {
+	client := agent.Client{}
+
+	client.ListAppPools()
+}
+
+func TestReassignAppPoolUsages(t *testing.T) {
+	client := agent.Client{}
+
+	usages, _ := client.GetAppPoolUsages("IntegrationTestPool")
+	client.ReassignAppPoolUsages(usages, "DefaultAppPool")
+}
+
+func TestUpdateAppPoolProcessModel(t *testing.T) {
+	client := agent.Client{}
+
+	client.GetServerVersion()
+	pool := agent.ApplicationPool{
+		Name:         "IntegrationTestPool",
+		StartMode:    "AlwaysRunning",
+		PipelineMode: "Integrated",
+		QueueLength:  1000,
+		ProcessModel: agent.ProcessModel{
+			IdentityType:                  "ApplicationPoolIdentity",
+			IdleTimeout:                   agent.TimeSpan(20 * time.Minute),
+			IdleTimeoutAction:             "Suspend",
+			MaxProcesses:                  1,
+			LogIdleTimeoutEvent:           true,
+			LogonType:                     "LogonService",
+			ManualGroupMembership:         true,
+			SetProfileEnvironment:         true,
+			RequestQueueDelegatorIdentity: `IIS APPPOOL\Delegator`,
+		},
+	}
+	client.UpdateAppPool(pool)
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func TestBindingInformationRoundTrip(t *testing.T) {
+	cases := []struct {
+		information string
+		ip          string
+		port        int
+		hostHeader  string
+	}{
+		{"*:80:", "*", 80, ""},
+		{"10.0.0.1:443:www.example.com", "10.0.0.1", 443, "www.example.com"},
+		{"[::1]:443:localhost", "::1", 443, "localhost"},
+		{"[fe80::1:2:3]:8080:", "fe80::1:2:3", 8080, ""},
+	}
+
+	for _, c := range cases {
+		ip, port, hostHeader, err := agent.ParseBindingInformation(c.information)
+		if err != nil {
+			t.Fatalf("parsing %q: %s", c.information, err)
+		}
+
+		if ip != c.ip || port != c.port || hostHeader != c.hostHeader {
+			t.Errorf("parsing %q: got %q, %d, %q", c.information, ip, port, hostHeader)
+		}
+
+		if formatted := agent.FormatBindingInformation(ip, port, hostHeader); formatted != c.information {
+			t.Errorf("formatting %q: got %q", c.information, formatted)
+		}
+	}
+}
+
+func TestInvalidBindingInformation(t *testing.T) {
+	for _, information := range []string{"808:*", "*", "[::1:443:", "*:http:"} {
+		if _, _, _, err := agent.ParseBindingInformation(information); err == nil {
+			t.Errorf("parsing %q: expected an error", information)
+		}
+	}
+}
+
+func TestBindingInformationOfBinding(t *testing.T) {
+	cases := []struct {
+		binding     agent.Binding
+		information string
+	}{
+		{agent.Binding{Protocol: "https", Ip: "*", Port: 443, HostHeader: "www.example.com"}, "*:443:www.example.com"},
+		{agent.Binding{Protocol: "http", Ip: "::1", Port: 80}, "[::1]:80:"},
+		{agent.Binding{Protocol: "net.tcp", Information: "808:*"}, "808:*"},
+	}
+
+	for _, c := range cases {
+		if information := c.binding.BindingInformation(); information != c.information {
+			t.Errorf("binding %s: got %q, expected %q", c.binding.Protocol, information, c.information)
+		}
+	}
+}
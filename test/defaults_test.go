@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func TestUpdateAppPoolDefaults(t *testing.T) {
+	client := agent.Client{}
+
+	defaults := agent.StockApplicationPoolDefaults
+	defaults.StartMode = "AlwaysRunning"
+	defaults.ProcessModel.IdleTimeout = agent.TimeSpan(25 * time.Hour)
+	client.UpdateAppPoolDefaults(defaults)
+	client.GetAppPoolDefaults()
+	client.UpdateAppPoolDefaults(agent.StockApplicationPoolDefaults)
+}
+
+func TestUpdateSiteDefaults(t *testing.T) {
+	client := agent.Client{}
+
+	defaults := agent.StockSiteDefaults
+	logFile := *defaults.LogFile
+	logFile.Directory = `D:\Logs\IIS`
+	logFile.Period = "Hourly"
+	defaults.LogFile = &logFile
+	client.UpdateSiteDefaults(defaults)
+	client.GetSiteDefaults()
+	client.UpdateSiteDefaults(agent.StockSiteDefaults)
+}
+
+func TestUpdateApplicationDefaults(t *testing.T) {
+	client := agent.Client{}
+
+	defaults := agent.StockApplicationDefaults
+	defaults.PreloadEnabled = true
+	client.UpdateApplicationDefaults(defaults)
+	client.GetApplicationDefaults()
+	client.UpdateApplicationDefaults(agent.StockApplicationDefaults)
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func TestTimeSpanRoundTrip(t *testing.T) {
+	cases := map[string]time.Duration{
+		"00:00:00":         0,
+		"00:20:00":         20 * time.Minute,
+		"1.01:00:00":       1500 * time.Minute,
+		"1.05:00:00":       29 * time.Hour,
+		"30.00:00:00":      43200 * time.Minute,
+		"00:00:01.5000000": 1500 * time.Millisecond,
+		"-00:05:00":        -5 * time.Minute,
+	}
+
+	for text, expected := range cases {
+		parsed, err := agent.ParseTimeSpan(text)
+		if err != nil {
+			t.Fatalf("parsing %q: %s", text, err)
+		}
+
+		if time.Duration(parsed) != expected {
+			t.Errorf("parsing %q: expected %v, got %v", text, expected, time.Duration(parsed))
+		}
+
+		if parsed.String() != text {
+			t.Errorf("formatting %v: expected %q, got %q", expected, text, parsed.String())
+		}
+	}
+}
+
+func TestParseTimeSpanShortForms(t *testing.T) {
+	cases := map[string]time.Duration{
+		"2":     48 * time.Hour,
+		"05:30": 5*time.Hour + 30*time.Minute,
+	}
+
+	for text, expected := range cases {
+		parsed, err := agent.ParseTimeSpan(text)
+		if err != nil || time.Duration(parsed) != expected {
+			t.Errorf("parsing %q: expected %v, got %v (%v)", text, expected, time.Duration(parsed), err)
+		}
+	}
+
+	if _, err := agent.ParseTimeSpan("25:00:00"); err == nil {
+		t.Errorf("parsing 25:00:00 should fail")
+	}
+}
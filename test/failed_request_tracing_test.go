@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func TestPutFailedRequestTracingRule(t *testing.T) {
+	client := agent.Client{}
+	rule := agent.FailedRequestTracingRule{
+		WebSiteName: "Test",
+		Path:        "*.aspx",
+		StatusCodes: "500-599,404.2",
+		TimeTaken:   agent.TimeSpan(30 * time.Second),
+		Verbosity:   "Warning",
+		TraceAreas: []agent.TraceArea{
+			{Provider: "WWW Server", Areas: "Authentication,Security,Module", Verbosity: "Verbose"},
+			{Provider: "ASPNET", Areas: "Infrastructure,Module,Page,AppServices", Verbosity: "Verbose"},
+		},
+	}
+	client.PutFailedRequestTracingRule(rule)
+}
+
+func TestGetFailedRequestTracingRule(t *testing.T) {
+	client := agent.Client{}
+	client.GetFailedRequestTracingRule("Test", "*.aspx")
+}
+
+func TestDeleteFailedRequestTracingRule(t *testing.T) {
+	client := agent.Client{}
+	client.DeleteFailedRequestTracingRule("Test", "*.aspx")
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/rickedb/terraform-provider-iis/iis"
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracingExportsExecuteSpans(t *testing.T) {
+	var mu sync.Mutex
+	spans := map[string]map[string]string{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					attributes := map[string]string{}
+					for _, attribute := range span.Attributes {
+						attributes[attribute.Key] = attribute.Value.GetStringValue()
+					}
+					spans[span.Name] = attributes
+				}
+			}
+		}
+
+		response, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(response)
+	}))
+	defer collector.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+	ctx := context.Background()
+	shutdown, err := iis.ConfigureTracing(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := otel.Tracer("test").Start(ctx, "test")
+	agent.Client{}.WithContext(ctx).GetAppPool("DefaultAppPool")
+	span.End()
+
+	if err = shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	execute, ok := spans["Client.Execute"]
+	if !ok {
+		t.Fatalf("expected a Client.Execute span, got %v", spans)
+	}
+	if execute["iis.host"] != "localhost" {
+		t.Errorf("expected iis.host to be 'localhost', got %q", execute["iis.host"])
+	}
+	if _, ok = spans["powershell.start"]; !ok {
+		t.Errorf("expected a powershell.start span, got %v", spans)
+	}
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rickedb/terraform-provider-iis/iis/agent"
 )
@@ -32,6 +33,52 @@ func TestCreateWebSite(t *testing.T) {
 	client.CreateWebSite(webSite)
 }
 
+func TestCreateWebSiteWithId(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Id:                  "4242",
+		Name:                "TestWithId",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7274,
+			},
+		},
+	}
+	client.CreateWebSite(webSite)
+}
+
+func TestCreateWebSiteProvisioningPhysicalPath(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Name:                "TestProvisioning",
+		PhysicalPath:        "C:/inetpub/wwwroot/provisioning",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7275,
+			},
+		},
+		PhysicalPathProvisioning: agent.PhysicalPathProvisioning{
+			Create: true,
+			Permissions: []agent.PathPermission{
+				{Identity: `IIS AppPool\IntegrationTestPool`, Rights: "ReadAndExecute"},
+				{Identity: "BUILTIN\\Administrators", Rights: "FullControl"},
+			},
+		},
+	}
+	client.CreateWebSite(webSite)
+	client.DeleteWebSite(webSite.Name)
+	client.RemovePhysicalPath(webSite.PhysicalPath, true)
+}
+
 func TestUpdateWebSite(t *testing.T) {
 
 	client := agent.Client{}
@@ -56,3 +103,182 @@ func TestUpdateWebSite(t *testing.T) {
 	}
 	client.UpdateWebSite(webSite)
 }
+
+func TestUpdateWebSiteHttpsBinding(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Name:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:                    "*",
+				Protocol:              "https",
+				Port:                  7443,
+				HostHeader:            "test",
+				CertificateThumbprint: "0123456789ABCDEF0123456789ABCDEF01234567",
+				CertificateStoreName:  "My",
+				SslFlags:              []string{"Sni", "DisableLegacyTLS"},
+			},
+		},
+	}
+	client.UpdateWebSite(webSite)
+}
+
+func TestUpdateWebSiteLimits(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Name:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7272,
+			},
+		},
+		Limits: &agent.Limits{
+			MaxBandwidth:      1048576,
+			MaxConnections:    500,
+			ConnectionTimeout: agent.TimeSpan(36 * time.Hour),
+			MaxUrlSegments:    64,
+		},
+	}
+	client.UpdateWebSite(webSite)
+}
+
+func TestUpdateWebSiteLogFile(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Name:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7272,
+			},
+		},
+		LogFile: &agent.LogFile{
+			Enabled:         true,
+			LogFormat:       "W3C",
+			Directory:       `D:\Logs`,
+			Period:          "Hourly",
+			TruncateSize:    20971520,
+			LogExtFileFlags: agent.FlagList{"Date", "Time", "ClientIP", "UriStem", "HttpStatus", "TimeTaken"},
+			LogTargetW3C:    agent.FlagList{"File", "ETW"},
+			CustomFields: []agent.LogCustomField{
+				{LogFieldName: "X-Forwarded-For", SourceName: "X-Forwarded-For", SourceType: "RequestHeader"},
+				{LogFieldName: "CorrelationId", SourceName: "X-Correlation-Id", SourceType: "ResponseHeader"},
+			},
+		},
+	}
+	client.UpdateWebSite(webSite)
+}
+
+func TestUpdateWebSiteHsts(t *testing.T) {
+
+	client := agent.Client{}
+	webSite := agent.WebSite{
+		Name:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7272,
+			},
+		},
+		Hsts: &agent.Hsts{
+			Enabled:             true,
+			MaxAge:              31536000,
+			IncludeSubDomains:   true,
+			RedirectHttpToHttps: true,
+		},
+	}
+	client.UpdateWebSite(webSite)
+}
+
+func TestUpdateWebSiteCredentials(t *testing.T) {
+
+	client := agent.Client{}
+	password := "P@ss\"word'"
+	webSite := agent.WebSite{
+		Name:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test",
+		ApplicationPoolName: "IntegrationTestPool",
+		Username:            `CONTOSO\content-reader`,
+		Password:            &password,
+		LogonMethod:         "Network",
+		Bindings: []agent.Binding{
+			{
+				Ip:       "*",
+				Protocol: "http",
+				Port:     7272,
+			},
+		},
+	}
+	client.UpdateWebSite(webSite)
+}
+
+func TestServerVersionSupportsHsts(t *testing.T) {
+	cases := map[agent.ServerVersion]bool{
+		{Major: 8, Minor: 5, WindowsBuild: 9600}:   false,
+		{Major: 10, Minor: 0, WindowsBuild: 14393}: false,
+		{Major: 10, Minor: 0, WindowsBuild: 16299}: true,
+		{Major: 10, Minor: 0, WindowsBuild: 20348}: true,
+	}
+
+	for version, expected := range cases {
+		if version.SupportsHsts() != expected {
+			t.Errorf("%s: expected %t", version, expected)
+		}
+	}
+}
+
+func TestStopAndStartWebSite(t *testing.T) {
+
+	client := agent.Client{}
+	client.StopWebSite("Test")
+	client.GetWebSiteState("Test")
+	client.StartWebSite("Test")
+}
+
+func TestRestartWebSite(t *testing.T) {
+
+	client := agent.Client{}
+	client.RestartWebSite("Test")
+}
+
+func TestListWebSites(t *testing.T) {
+
+	client := agent.Client{}
+	client.ListWebSites()
+}
+
+func TestListWebApplications(t *testing.T) {
+
+	client := agent.Client{}
+	client.ListWebApplications()
+}
+
+func TestUpdateWebApplicationCredentials(t *testing.T) {
+
+	client := agent.Client{}
+	password := "P@ss\"word'"
+	client.UpdateWebApplication(agent.WebApplication{
+		Name:                "App",
+		Site:                "Test",
+		PhysicalPath:        "C:/inetpub/wwwroot/test/app",
+		ApplicationPoolName: "IntegrationTestPool",
+		Username:            `CONTOSO\content-reader`,
+		Password:            &password,
+		LogonMethod:         "Network",
+	})
+}
@@ -0,0 +1,19 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/rickedb/terraform-provider-iis/iis/agent"
+)
+
+func TestGetWorkerProcesses(t *testing.T) {
+	client := agent.Client{}
+
+	client.GetWorkerProcesses("IntegrationTestPool", 0)
+}
+
+func TestGetAllWorkerProcesses(t *testing.T) {
+	client := agent.Client{}
+
+	client.GetWorkerProcesses("", 1000)
+}